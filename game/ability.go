@@ -0,0 +1,112 @@
+package game
+
+import "math"
+
+type Ability struct {
+	Name     string
+	Cooldown float64
+	Strength int
+	Radius   float64
+	Duration float64
+}
+
+var abilities = map[string]Ability{
+	"dash":          {Name: "dash", Cooldown: 4.0, Strength: 30},
+	"barrier_burst": {Name: "barrier_burst", Cooldown: 12.0, Strength: 60, Duration: 4.0},
+	"shockwave":     {Name: "shockwave", Cooldown: 8.0, Strength: 20, Radius: 150},
+}
+
+func (p *Player) SetAbility(name string) {
+	if _, ok := abilities[name]; !ok {
+		return
+	}
+
+	p.Ability = name
+	p.AbilityCooldown = 0
+}
+
+func (p *Player) CanUseAbility() bool {
+	return p.Ability != "" && p.AbilityCooldown <= 0
+}
+
+func (p *Player) AbilityMaxCooldown() float64 {
+	return abilities[p.Ability].Cooldown
+}
+
+func (p *Player) Dash(impulse float64) {
+	dirX := 0.0
+	dirY := 0.0
+
+	if p.Input.W {
+		dirY -= 1
+	}
+	if p.Input.S {
+		dirY += 1
+	}
+	if p.Input.A {
+		dirX -= 1
+	}
+	if p.Input.D {
+		dirX += 1
+	}
+
+	if dirX == 0 && dirY == 0 {
+		dirX = p.VelocityX
+		dirY = p.VelocityY
+	}
+
+	length := math.Sqrt(dirX*dirX + dirY*dirY)
+	if length == 0 {
+		return
+	}
+
+	p.VelocityX += dirX / length * impulse
+	p.VelocityY += dirY / length * impulse
+}
+
+func (p *Player) BurstBarrier(amount int, duration float64) {
+	p.BurstBarrierAmount = amount
+	p.BurstBarrierRemaining = duration
+}
+
+func (w *World) useAbility(p *Player) {
+	p.AbilityRequested = false
+
+	if !p.CanUseAbility() {
+		return
+	}
+
+	ability := abilities[p.Ability]
+	switch ability.Name {
+	case "dash":
+		p.Dash(float64(ability.Strength))
+	case "barrier_burst":
+		p.BurstBarrier(ability.Strength, ability.Duration)
+	case "shockwave":
+		w.shockwave(p, ability)
+	}
+
+	p.AbilityCooldown = ability.Cooldown
+}
+
+func (w *World) shockwave(p *Player, ability Ability) {
+	radius := ability.Radius + float64(p.Size)
+
+	for _, target := range w.playerSlice {
+		if target.ID == p.ID || !target.IsAlive() || !w.canHurt(p, target) {
+			continue
+		}
+
+		dx := target.X - p.X
+		dy := target.Y - p.Y
+		reach := radius + float64(target.Size)
+		if dx*dx+dy*dy > reach*reach {
+			continue
+		}
+
+		p.PushPlayer(target, float64(ability.Strength))
+		if target.TakeDamage(ability.Strength) {
+			w.handlePlayerDeath(target, p)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package game
+
+import "sort"
+
+type CaptureTheOrbConfig struct {
+	CapturesToWin int
+	TimeLimit     float64
+	ResultsTime   float64
+}
+
+type CaptureTheOrbState struct {
+	Mode          string         `json:"mode"`
+	Phase         string         `json:"phase"`
+	TimeRemaining float64        `json:"time_remaining"`
+	Bases         []TeamBase     `json:"bases"`
+	Orbs          []FlagOrb      `json:"orbs"`
+	Points        map[string]int `json:"points"`
+	CapturesToWin int            `json:"captures_to_win"`
+	WinnerID      string         `json:"winner_id,omitempty"`
+}
+
+type CaptureTheOrb struct {
+	World  *World
+	Config CaptureTheOrbConfig
+
+	Phase         string
+	TimeRemaining float64
+	Points        map[string]int
+	WinnerID      string
+
+	onEvent func(eventType string, data any)
+}
+
+func DefaultCaptureTheOrbConfig() CaptureTheOrbConfig {
+	return CaptureTheOrbConfig{
+		CapturesToWin: 3,
+		TimeLimit:     900,
+		ResultsTime:   15,
+	}
+}
+
+func NewCaptureTheOrb(world *World, config CaptureTheOrbConfig) *CaptureTheOrb {
+	c := &CaptureTheOrb{
+		World:  world,
+		Config: config,
+	}
+
+	world.Mu.Lock()
+	c.start()
+	world.Mu.Unlock()
+
+	return c
+}
+
+func (c *CaptureTheOrb) SetEventHandler(handler func(eventType string, data any)) {
+	c.onEvent = handler
+}
+
+func (c *CaptureTheOrb) State() any {
+	return c.state()
+}
+
+func (c *CaptureTheOrb) state() CaptureTheOrbState {
+	bases := make([]TeamBase, 0, len(c.World.Bases))
+	for _, base := range c.World.Bases {
+		bases = append(bases, *base)
+	}
+
+	orbs := make([]FlagOrb, 0, len(c.World.Orbs))
+	for _, orb := range c.World.Orbs {
+		orbs = append(orbs, *orb)
+	}
+
+	points := make(map[string]int, len(c.Points))
+	for team, value := range c.Points {
+		points[team] = value
+	}
+
+	return CaptureTheOrbState{
+		Mode:          "cto",
+		Phase:         c.Phase,
+		TimeRemaining: c.TimeRemaining,
+		Bases:         bases,
+		Orbs:          orbs,
+		Points:        points,
+		CapturesToWin: c.Config.CapturesToWin,
+		WinnerID:      c.WinnerID,
+	}
+}
+
+func (c *CaptureTheOrb) Update(deltaTime float64) {
+	c.World.Update(deltaTime)
+
+	c.World.Mu.Lock()
+	defer c.World.Mu.Unlock()
+
+	c.TimeRemaining -= deltaTime
+
+	switch c.Phase {
+	case "running":
+		for _, event := range c.World.updateOrbs(deltaTime) {
+			if event.Type == "capture" {
+				c.Points[event.TeamID]++
+			}
+			if c.onEvent != nil {
+				c.onEvent("orb_event", event)
+			}
+		}
+
+		if leader := c.leader(); leader != "" && c.Points[leader] >= c.Config.CapturesToWin {
+			c.finish(leader)
+		} else if c.TimeRemaining <= 0 {
+			c.finish(leader)
+		}
+
+	case "finished":
+		if c.TimeRemaining <= 0 {
+			c.World.resetLocked()
+			c.start()
+		}
+	}
+}
+
+func (c *CaptureTheOrb) leader() string {
+	teams := make([]string, 0, len(c.Points))
+	for team := range c.Points {
+		teams = append(teams, team)
+	}
+	if len(teams) == 0 {
+		return ""
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		if c.Points[teams[i]] != c.Points[teams[j]] {
+			return c.Points[teams[i]] > c.Points[teams[j]]
+		}
+		return teams[i] < teams[j]
+	})
+
+	if len(teams) > 1 && c.Points[teams[0]] == c.Points[teams[1]] {
+		return ""
+	}
+	return teams[0]
+}
+
+func (c *CaptureTheOrb) start() {
+	c.Phase = "running"
+	c.TimeRemaining = c.Config.TimeLimit
+	c.WinnerID = ""
+	c.Points = make(map[string]int)
+	c.World.setupOrbs()
+	c.emit()
+}
+
+func (c *CaptureTheOrb) finish(winner string) {
+	c.Phase = "finished"
+	c.TimeRemaining = c.Config.ResultsTime
+	c.WinnerID = winner
+	c.emit()
+}
+
+func (c *CaptureTheOrb) emit() {
+	if c.onEvent != nil {
+		c.onEvent("match_phase", c.state())
+	}
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"bytes"
 	"encoding/json"
 	"math/rand"
 	"os"
@@ -23,6 +24,32 @@ type CardEffect struct {
 	AuraRadius   float64 `json:"aura_radius,omitempty"`
 	AuraStrength float64 `json:"aura_strength,omitempty"`
 	AuraTick     float64 `json:"aura_tick,omitempty"`
+
+	EffectType     string  `json:"effect_type,omitempty"`
+	EffectStrength float64 `json:"effect_strength,omitempty"`
+	EffectDuration float64 `json:"effect_duration,omitempty"`
+	EffectTick     float64 `json:"effect_tick,omitempty"`
+
+	Ability string `json:"ability,omitempty"`
+
+	ProjectileDamage   float64 `json:"projectile_damage,omitempty"`
+	ProjectileInterval float64 `json:"projectile_interval,omitempty"`
+	ProjectileRange    float64 `json:"projectile_range,omitempty"`
+	ProjectileSpeed    float64 `json:"projectile_speed,omitempty"`
+}
+
+type RarityWeight struct {
+	Weight        int     `json:"weight"`
+	ScoreBonus    float64 `json:"score_bonus,omitempty"`
+	MaxScoreBonus int     `json:"max_score_bonus,omitempty"`
+	PityBonus     int     `json:"pity_bonus,omitempty"`
+	ResetsPity    bool    `json:"resets_pity,omitempty"`
+}
+
+type CardData struct {
+	Rarities     map[string]RarityWeight `json:"rarities"`
+	OfferTimeout float64                 `json:"offer_timeout,omitempty"`
+	Cards        []Card                  `json:"cards"`
 }
 
 type CardSet struct {
@@ -33,8 +60,26 @@ type CardSet struct {
 
 var allCards []Card
 var cardSets []CardSet
+var rarityWeights map[string]RarityWeight
+var cardOfferTimeout = 20.0
+
+var rarityRanks = map[string]int{
+	"Common":    1,
+	"Uncommon":  2,
+	"Rare":      3,
+	"Epic":      4,
+	"Legendary": 5,
+}
 
 func init() {
+	rarityWeights = map[string]RarityWeight{
+		"Common":    {Weight: 100},
+		"Uncommon":  {Weight: 50},
+		"Rare":      {Weight: 20},
+		"Epic":      {Weight: 8, ResetsPity: true},
+		"Legendary": {Weight: 3, ResetsPity: true},
+	}
+
 	cardSets = []CardSet{
 		{
 			Name:  "Berserker Set",
@@ -101,21 +146,59 @@ func LoadCards(path string) error {
 		return err
 	}
 
-	if err := json.Unmarshal(data, &allCards); err != nil {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return json.Unmarshal(trimmed, &allCards)
+	}
+
+	var cardData CardData
+	if err := json.Unmarshal(trimmed, &cardData); err != nil {
 		return err
 	}
 
+	allCards = cardData.Cards
+	if len(cardData.Rarities) > 0 {
+		rarityWeights = cardData.Rarities
+	}
+	if cardData.OfferTimeout > 0 {
+		cardOfferTimeout = cardData.OfferTimeout
+	}
+
 	return nil
 }
 
-func GetRandomCards(count int, appliedCardNames []string) []Card {
+func RarityWeights(score int, offersSincePity int) map[string]int {
+	weights := make(map[string]int, len(rarityWeights))
+	for rarity, rw := range rarityWeights {
+		weight := rw.Weight
+
+		scoreBonus := int(rw.ScoreBonus * float64(score) / 100)
+		if rw.MaxScoreBonus > 0 && scoreBonus > rw.MaxScoreBonus {
+			scoreBonus = rw.MaxScoreBonus
+		}
+		weight += scoreBonus
+		weight += rw.PityBonus * offersSincePity
+
+		weights[rarity] = weight
+	}
+	return weights
+}
+
+func ResetsPity(rarity string) bool {
+	return rarityWeights[rarity].ResetsPity
+}
+
+func GetRandomCards(rng *rand.Rand, count int, weights map[string]int, appliedCardNames []string, banishedCardNames []string) []Card {
 	if len(allCards) == 0 {
 		return []Card{}
 	}
 
-	appliedMap := make(map[string]bool)
+	excludedMap := make(map[string]bool)
 	for _, name := range appliedCardNames {
-		appliedMap[name] = true
+		excludedMap[name] = true
+	}
+	for _, name := range banishedCardNames {
+		excludedMap[name] = true
 	}
 
 	setProgress := make(map[string]int)
@@ -129,14 +212,6 @@ func GetRandomCards(count int, appliedCardNames []string) []Card {
 		}
 	}
 
-	rarityWeights := map[string]int{
-		"Common":    100,
-		"Uncommon":  50,
-		"Rare":      20,
-		"Epic":      8,
-		"Legendary": 3,
-	}
-
 	type weightedCard struct {
 		card   Card
 		weight int
@@ -144,11 +219,11 @@ func GetRandomCards(count int, appliedCardNames []string) []Card {
 
 	weightedPool := make([]weightedCard, 0)
 	for _, card := range allCards {
-		if appliedMap[card.Name] {
+		if excludedMap[card.Name] {
 			continue
 		}
 
-		weight := rarityWeights[card.Rarity]
+		weight := weights[card.Rarity]
 		if weight == 0 {
 			weight = 10
 		}
@@ -175,7 +250,7 @@ func GetRandomCards(count int, appliedCardNames []string) []Card {
 	usedIndices := make(map[int]bool)
 
 	for len(selected) < count && len(usedIndices) < len(weightedPool) {
-		roll := rand.Intn(totalWeight)
+		roll := rng.Intn(totalWeight)
 		currentWeight := 0
 
 		for idx, wc := range weightedPool {
@@ -205,6 +280,59 @@ func GetCardByID(id uint64) *Card {
 	return nil
 }
 
+func (p *Player) RollCardOffer(count int) []Card {
+	weights := RarityWeights(p.Score, p.OffersSincePity)
+	cards := GetRandomCards(p.CardRNG, count, weights, p.AppliedCards, p.BanishedCards)
+
+	p.OffersSincePity++
+	for _, card := range cards {
+		if ResetsPity(card.Rarity) {
+			p.OffersSincePity = 0
+			break
+		}
+	}
+
+	p.SetOfferedCards(cards)
+	return cards
+}
+
+func (p *Player) ApplyCard(card *Card) {
+	for _, effect := range card.Effects {
+		p.ApplyCardEffect(effect)
+	}
+
+	p.AppliedCards = append(p.AppliedCards, card.Name)
+	if len(p.AppliedCards)%3 == 0 {
+		p.Rerolls++
+	}
+	p.CalculateSetBonuses()
+	p.UpdateNextCardScore()
+}
+
+func (p *Player) BestOfferedCard() *Card {
+	var best *Card
+	bestScore := -1
+
+	for _, id := range p.OfferedCards {
+		card := GetCardByID(id)
+		if card == nil {
+			continue
+		}
+
+		score := rarityRanks[card.Rarity]
+		if card.Set != "" {
+			score += p.SetBonuses[card.Set] * 10
+		}
+
+		if score > bestScore {
+			best = card
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
 func (p *Player) CalculateSetBonuses() {
 	p.SetBonuses = make(map[string]int)
 
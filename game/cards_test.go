@@ -0,0 +1,97 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func offerIDs(cards []Card) []uint64 {
+	ids := make([]uint64, len(cards))
+	for i, card := range cards {
+		ids[i] = card.ID
+	}
+	return ids
+}
+
+func TestRollCardOfferIsReproducibleForSeed(t *testing.T) {
+	a := NewPlayer("a", 0, 0, 42)
+	b := NewPlayer("b", 0, 0, 42)
+	c := NewPlayer("c", 0, 0, 43)
+
+	same := true
+	for i := 0; i < 20; i++ {
+		offerA := offerIDs(a.RollCardOffer(3))
+		offerB := offerIDs(b.RollCardOffer(3))
+		offerC := offerIDs(c.RollCardOffer(3))
+
+		if !slices.Equal(offerA, offerB) {
+			t.Fatalf("roll %d differs for the same seed: %v vs %v", i, offerA, offerB)
+		}
+		if !slices.Equal(offerA, offerC) {
+			same = false
+		}
+	}
+
+	if same {
+		t.Fatal("different seeds rolled identical offers")
+	}
+}
+
+func TestRollCardOfferPity(t *testing.T) {
+	p := NewPlayer("a", 0, 0, 7)
+
+	increments, resets := 0, 0
+	for i := 0; i < 300; i++ {
+		before := p.OffersSincePity
+		cards := p.RollCardOffer(3)
+
+		reset := slices.ContainsFunc(cards, func(card Card) bool {
+			return ResetsPity(card.Rarity)
+		})
+
+		if reset {
+			resets++
+			if p.OffersSincePity != 0 {
+				t.Fatalf("roll %d offered a pity card but counter is %d", i, p.OffersSincePity)
+			}
+		} else {
+			increments++
+			if p.OffersSincePity != before+1 {
+				t.Fatalf("roll %d: counter went %d -> %d", i, before, p.OffersSincePity)
+			}
+		}
+	}
+
+	if increments == 0 || resets == 0 {
+		t.Fatalf("expected both increments and resets, got %d and %d", increments, resets)
+	}
+}
+
+func TestRarityWeightsGrowWithPity(t *testing.T) {
+	base := RarityWeights(0, 0)
+	pity := RarityWeights(0, 10)
+
+	for rarity, weight := range base {
+		if ResetsPity(rarity) && pity[rarity] <= weight {
+			t.Fatalf("%s weight did not grow with pity: %d -> %d", rarity, weight, pity[rarity])
+		}
+	}
+}
+
+func TestRerollsOnlyComeFromAppliedCards(t *testing.T) {
+	p := NewPlayer("a", 0, 0, 1)
+
+	for i := 0; i < 5; i++ {
+		p.UpdateNextCardScore()
+	}
+	if p.Rerolls != 0 {
+		t.Fatalf("got %d rerolls without applying a card", p.Rerolls)
+	}
+
+	for _, card := range allCards[:6] {
+		p.ApplyCard(&card)
+	}
+	if p.Rerolls != 2 {
+		t.Fatalf("got %d rerolls after 6 cards, want 2", p.Rerolls)
+	}
+}
@@ -0,0 +1,59 @@
+package game
+
+import "slices"
+
+const emoteDuration = 3.0
+
+type Achievement struct {
+	ID     string
+	Emote  string
+	Earned func(p *Player) bool
+}
+
+var baseEmotes = []string{"taunt", "gg", "help"}
+
+var achievements = []Achievement{
+	{ID: "first_blood", Emote: "skull", Earned: func(p *Player) bool { return p.Kills >= 1 }},
+	{ID: "hoarder", Emote: "crown", Earned: func(p *Player) bool { return p.Score >= 1000 }},
+	{ID: "collector", Emote: "sparkle", Earned: func(p *Player) bool { return len(p.AppliedCards) >= 9 }},
+	{ID: "exterminator", Emote: "fire", Earned: func(p *Player) bool { return p.Kills >= 10 }},
+}
+
+func IsEmote(id string) bool {
+	if slices.Contains(baseEmotes, id) {
+		return true
+	}
+	for _, a := range achievements {
+		if a.Emote == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Player) CanEmote(id string) bool {
+	return slices.Contains(p.Emotes, id)
+}
+
+func (p *Player) SetEmote(id string) {
+	p.Emote = id
+	p.EmoteRemaining = emoteDuration
+}
+
+func (p *Player) CheckAchievements() {
+	for _, a := range achievements {
+		if slices.Contains(p.Achievements, a.ID) || !a.Earned(p) {
+			continue
+		}
+		p.Achievements = append(p.Achievements, a.ID)
+		p.Emotes = append(p.Emotes, a.Emote)
+	}
+}
+
+func (w *World) RequestEmote(id, emote string) {
+	w.Submit(func(w *World) {
+		if player, ok := w.Players[id]; ok && player.CanEmote(emote) {
+			player.SetEmote(emote)
+		}
+	})
+}
@@ -0,0 +1,336 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+)
+
+type Map struct {
+	Name        string       `json:"name"`
+	Size        float64      `json:"size"`
+	Walls       []Wall       `json:"walls"`
+	PelletZones []PelletZone `json:"pellet_zones"`
+	SpawnZones  []SpawnZone  `json:"spawn_zones"`
+
+	Bases         []TeamBase     `json:"bases"`
+	Hazards       []HazardZone   `json:"hazards"`
+	RandomHazards *RandomHazards `json:"random_hazards,omitempty"`
+	DeathDrops    *DeathDrops    `json:"death_drops,omitempty"`
+}
+
+type Wall struct {
+	Type   string       `json:"type"`
+	X      float64      `json:"x"`
+	Y      float64      `json:"y"`
+	Radius float64      `json:"radius,omitempty"`
+	Width  float64      `json:"width,omitempty"`
+	Height float64      `json:"height,omitempty"`
+	Points [][2]float64 `json:"points,omitempty"`
+}
+
+type PelletZone struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+	Weight float64 `json:"weight"`
+}
+
+type SpawnZone struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+	Team   string  `json:"team,omitempty"`
+}
+
+func NewEmptyMap(size float64) *Map {
+	return &Map{
+		Name:        "empty",
+		Size:        size,
+		Walls:       []Wall{},
+		PelletZones: []PelletZone{},
+		SpawnZones:  []SpawnZone{},
+		Bases:       []TeamBase{},
+		Hazards:     []HazardZone{},
+	}
+}
+
+func LoadMap(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid map %s: %w", path, err)
+	}
+
+	return &m, nil
+}
+
+func (m *Map) Validate() error {
+	if m.Size <= 0 {
+		return fmt.Errorf("size must be positive, got %v", m.Size)
+	}
+
+	for i, wall := range m.Walls {
+		switch wall.Type {
+		case "circle":
+			if wall.Radius <= 0 {
+				return fmt.Errorf("wall %d: circle radius must be positive", i)
+			}
+		case "rect":
+			if wall.Width <= 0 || wall.Height <= 0 {
+				return fmt.Errorf("wall %d: rect width and height must be positive", i)
+			}
+		case "polygon":
+			if len(wall.Points) < 3 {
+				return fmt.Errorf("wall %d: polygon needs at least 3 points", i)
+			}
+		default:
+			return fmt.Errorf("wall %d: unknown type %q", i, wall.Type)
+		}
+	}
+
+	for i, zone := range m.PelletZones {
+		if zone.Radius <= 0 || zone.Weight < 0 {
+			return fmt.Errorf("pellet zone %d: radius must be positive and weight non-negative", i)
+		}
+	}
+
+	for i, zone := range m.SpawnZones {
+		if zone.Radius <= 0 {
+			return fmt.Errorf("spawn zone %d: radius must be positive", i)
+		}
+	}
+
+	for i, base := range m.Bases {
+		if base.TeamID == "" || base.Radius <= 0 {
+			return fmt.Errorf("base %d: team and a positive radius are required", i)
+		}
+	}
+
+	for i, zone := range m.Hazards {
+		if _, ok := hazardStrengths[zone.Type]; !ok {
+			return fmt.Errorf("hazard %d: unknown type %q", i, zone.Type)
+		}
+		if zone.Radius <= 0 {
+			return fmt.Errorf("hazard %d: radius must be positive", i)
+		}
+	}
+
+	if m.DeathDrops != nil {
+		if err := m.DeathDrops.Validate(); err != nil {
+			return fmt.Errorf("death drops: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Map) Blocks(x, y, radius float64) bool {
+	for i := range m.Walls {
+		if _, _, hit := m.Walls[i].Penetration(x, y, radius); hit {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Map) RandomPelletPoint() (float64, float64) {
+	totalWeight := 0.0
+	for _, zone := range m.PelletZones {
+		totalWeight += zone.Weight
+	}
+
+	roll := rand.Float64()
+	if roll < totalWeight {
+		for _, zone := range m.PelletZones {
+			if roll < zone.Weight {
+				return randomPointInCircle(zone.X, zone.Y, zone.Radius)
+			}
+			roll -= zone.Weight
+		}
+	}
+
+	return m.randomPoint()
+}
+
+func (m *Map) RandomSpawnPoint(teamID string) (float64, float64) {
+	zones := make([]SpawnZone, 0, len(m.SpawnZones))
+	for _, zone := range m.SpawnZones {
+		if zone.Team == teamID {
+			zones = append(zones, zone)
+		}
+	}
+	if len(zones) == 0 {
+		zones = m.SpawnZones
+	}
+
+	if len(zones) == 0 {
+		return m.randomPoint()
+	}
+
+	zone := zones[rand.Intn(len(zones))]
+	return randomPointInCircle(zone.X, zone.Y, zone.Radius)
+}
+
+func (m *Map) randomPoint() (float64, float64) {
+	x := rand.Float64()*m.Size - m.Size/2
+	y := rand.Float64()*m.Size - m.Size/2
+	return x, y
+}
+
+func randomPointInCircle(cx, cy, radius float64) (float64, float64) {
+	angle := rand.Float64() * 2 * math.Pi
+	distance := math.Sqrt(rand.Float64()) * radius
+	return cx + math.Cos(angle)*distance, cy + math.Sin(angle)*distance
+}
+
+func (wall *Wall) Penetration(x, y, radius float64) (float64, float64, bool) {
+	switch wall.Type {
+	case "circle":
+		return circlePenetration(wall.X, wall.Y, wall.Radius, x, y, radius)
+	case "rect":
+		return rectPenetration(wall.X, wall.Y, wall.Width, wall.Height, x, y, radius)
+	case "polygon":
+		return polygonPenetration(wall.Points, x, y, radius)
+	}
+	return 0, 0, false
+}
+
+func circlePenetration(cx, cy, cr, x, y, radius float64) (float64, float64, bool) {
+	dx := x - cx
+	dy := y - cy
+	minDist := cr + radius
+	distSq := dx*dx + dy*dy
+	if distSq >= minDist*minDist {
+		return 0, 0, false
+	}
+
+	dist := math.Sqrt(distSq)
+	if dist == 0 {
+		return minDist, 0, true
+	}
+
+	depth := minDist - dist
+	return dx / dist * depth, dy / dist * depth, true
+}
+
+func rectPenetration(rx, ry, width, height, x, y, radius float64) (float64, float64, bool) {
+	left := rx - width/2
+	right := rx + width/2
+	top := ry - height/2
+	bottom := ry + height/2
+
+	closestX := math.Max(left, math.Min(x, right))
+	closestY := math.Max(top, math.Min(y, bottom))
+
+	if closestX == x && closestY == y {
+		pushLeft := x - left + radius
+		pushRight := right - x + radius
+		pushUp := y - top + radius
+		pushDown := bottom - y + radius
+
+		minPush := math.Min(math.Min(pushLeft, pushRight), math.Min(pushUp, pushDown))
+		switch minPush {
+		case pushLeft:
+			return -pushLeft, 0, true
+		case pushRight:
+			return pushRight, 0, true
+		case pushUp:
+			return 0, -pushUp, true
+		default:
+			return 0, pushDown, true
+		}
+	}
+
+	return circlePenetration(closestX, closestY, 0, x, y, radius)
+}
+
+func polygonPenetration(points [][2]float64, x, y, radius float64) (float64, float64, bool) {
+	if len(points) < 3 {
+		return 0, 0, false
+	}
+
+	closestX, closestY := 0.0, 0.0
+	closestDistSq := math.MaxFloat64
+	inside := false
+
+	for i := range points {
+		ax, ay := points[i][0], points[i][1]
+		bx, by := points[(i+1)%len(points)][0], points[(i+1)%len(points)][1]
+
+		if (ay > y) != (by > y) && x < (bx-ax)*(y-ay)/(by-ay)+ax {
+			inside = !inside
+		}
+
+		ex := bx - ax
+		ey := by - ay
+		t := 0.0
+		if lengthSq := ex*ex + ey*ey; lengthSq > 0 {
+			t = math.Max(0, math.Min(1, ((x-ax)*ex+(y-ay)*ey)/lengthSq))
+		}
+
+		px := ax + ex*t
+		py := ay + ey*t
+		distSq := (x-px)*(x-px) + (y-py)*(y-py)
+		if distSq < closestDistSq {
+			closestX, closestY = px, py
+			closestDistSq = distSq
+		}
+	}
+
+	dist := math.Sqrt(closestDistSq)
+
+	if inside {
+		if dist == 0 {
+			return radius, 0, true
+		}
+		depth := dist + radius
+		return (closestX - x) / dist * depth, (closestY - y) / dist * depth, true
+	}
+
+	if dist >= radius {
+		return 0, 0, false
+	}
+	if dist == 0 {
+		return radius, 0, true
+	}
+
+	depth := radius - dist
+	return (x - closestX) / dist * depth, (y - closestY) / dist * depth, true
+}
+
+func (p *Player) ResolveWallCollisions(m *Map) {
+	radius := float64(p.Size)
+
+	for i := range m.Walls {
+		pushX, pushY, hit := m.Walls[i].Penetration(p.X, p.Y, radius)
+		if !hit {
+			continue
+		}
+
+		p.X += pushX
+		p.Y += pushY
+
+		length := math.Sqrt(pushX*pushX + pushY*pushY)
+		if length == 0 {
+			continue
+		}
+
+		nx := pushX / length
+		ny := pushY / length
+		if into := p.VelocityX*nx + p.VelocityY*ny; into < 0 {
+			p.VelocityX -= into * nx
+			p.VelocityY -= into * ny
+		}
+	}
+}
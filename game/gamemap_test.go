@@ -0,0 +1,70 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMapDeathDrops(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    DeathDrops
+		wantErr bool
+	}{
+		{
+			name: "missing block uses defaults",
+			json: `{"size": 2000}`,
+			want: DefaultDeathDrops(),
+		},
+		{
+			name: "partial block keeps other defaults",
+			json: `{"size": 2000, "death_drops": {"killer_share": 0.5, "drop_share": 0.4}}`,
+			want: DeathDrops{KillerShare: 0.5, DropShare: 0.4, PelletValue: 5, MaxPellets: 40, ScatterRadius: 120, Lifetime: 30},
+		},
+		{
+			name: "shares may drop nothing",
+			json: `{"size": 2000, "death_drops": {"killer_share": 0, "drop_share": 0}}`,
+			want: DeathDrops{PelletValue: 5, MaxPellets: 40, ScatterRadius: 120, Lifetime: 30},
+		},
+		{
+			name:    "shares over one are rejected",
+			json:    `{"size": 2000, "death_drops": {"killer_share": 0.5, "drop_share": 0.6}}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative share is rejected",
+			json:    `{"size": 2000, "death_drops": {"killer_share": -0.1}}`,
+			wantErr: true,
+		},
+		{
+			name:    "zero pellet value is rejected",
+			json:    `{"size": 2000, "death_drops": {"pellet_value": 0}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Map
+			if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			err := m.Validate()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected a validation error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := NewWorld(&m).DeathDrops; got != tt.want {
+				t.Fatalf("death drops = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+type HazardZone struct {
+	ID       string  `json:"id"`
+	Type     string  `json:"type"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Radius   float64 `json:"radius"`
+	Strength int     `json:"strength"`
+
+	OrbitX       float64 `json:"orbit_x,omitempty"`
+	OrbitY       float64 `json:"orbit_y,omitempty"`
+	OrbitRadius  float64 `json:"orbit_radius,omitempty"`
+	AngularSpeed float64 `json:"angular_speed,omitempty"`
+	Angle        float64 `json:"angle,omitempty"`
+
+	Lifetime float64 `json:"lifetime,omitempty"`
+}
+
+type RandomHazards struct {
+	Interval  float64  `json:"interval"`
+	MaxActive int      `json:"max_active"`
+	Lifetime  float64  `json:"lifetime"`
+	MinRadius float64  `json:"min_radius"`
+	MaxRadius float64  `json:"max_radius"`
+	Types     []string `json:"types"`
+}
+
+var hazardStrengths = map[string]int{
+	"lava":   6,
+	"mud":    50,
+	"spring": 4,
+	"danger": 15,
+}
+
+func NewHazardZone(hazardType string, x, y, radius float64, lifetime float64) *HazardZone {
+	return &HazardZone{
+		ID:       uuid.New().String(),
+		Type:     hazardType,
+		X:        x,
+		Y:        y,
+		Radius:   radius,
+		Strength: hazardStrengths[hazardType],
+		Lifetime: lifetime,
+	}
+}
+
+func (z *HazardZone) Update(deltaTime float64) {
+	if z.OrbitRadius > 0 {
+		z.Angle += z.AngularSpeed * deltaTime
+		z.X = z.OrbitX + math.Cos(z.Angle)*z.OrbitRadius
+		z.Y = z.OrbitY + math.Sin(z.Angle)*z.OrbitRadius
+	}
+
+	if z.Lifetime > 0 {
+		z.Lifetime -= deltaTime
+	}
+}
+
+func (z *HazardZone) Expired() bool {
+	return z.Lifetime < 0
+}
+
+func (z *HazardZone) Contains(p *Player) bool {
+	dx := p.X - z.X
+	dy := p.Y - z.Y
+	return dx*dx+dy*dy <= z.Radius*z.Radius
+}
+
+func (z *HazardZone) Apply(p *Player) {
+	switch z.Type {
+	case "lava":
+		p.RefreshEffect("burn", z.Strength, 1.0, 1.0, z.ID)
+	case "mud":
+		p.ApplySlow(float64(z.Strength)/100.0, 0.25)
+	case "spring":
+		p.RefreshEffect("regen", z.Strength, 1.0, 1.0, z.ID)
+	case "danger":
+		p.RefreshEffect("burn", z.Strength, 0.5, 0.5, z.ID)
+	}
+}
+
+func (w *World) updateHazards(deltaTime float64) {
+	i := 0
+	for i < len(w.Hazards) {
+		zone := w.Hazards[i]
+		zone.Update(deltaTime)
+
+		if zone.Expired() {
+			w.Hazards[i] = w.Hazards[len(w.Hazards)-1]
+			w.Hazards = w.Hazards[:len(w.Hazards)-1]
+			w.randomHazardCount--
+			continue
+		}
+
+		for _, player := range w.playerSlice {
+			if zone.Contains(player) {
+				zone.Apply(player)
+			}
+		}
+		i++
+	}
+
+	w.spawnRandomHazards(deltaTime)
+}
+
+func (w *World) spawnRandomHazards(deltaTime float64) {
+	config := w.Map.RandomHazards
+	if config == nil || config.Interval <= 0 || len(config.Types) == 0 {
+		return
+	}
+
+	w.hazardSpawnTimer += deltaTime
+	if w.hazardSpawnTimer < config.Interval {
+		return
+	}
+	w.hazardSpawnTimer = 0
+
+	if w.randomHazardCount >= config.MaxActive {
+		return
+	}
+
+	hazardType := config.Types[rand.Intn(len(config.Types))]
+	radius := config.MinRadius + rand.Float64()*(config.MaxRadius-config.MinRadius)
+	x, y := w.Map.randomPoint()
+
+	w.Hazards = append(w.Hazards, NewHazardZone(hazardType, x, y, radius, config.Lifetime))
+	w.randomHazardCount++
+}
@@ -0,0 +1,250 @@
+package game
+
+import (
+	"math"
+	"sort"
+)
+
+type KingOfTheHillConfig struct {
+	ZoneRadius      float64
+	MoveInterval    float64
+	PointsPerSecond int
+	ScoreToWin      int
+	TimeLimit       float64
+	ResultsTime     float64
+}
+
+type CaptureZone struct {
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Radius    float64 `json:"radius"`
+	OwnerID   string  `json:"owner_id,omitempty"`
+	Contested bool    `json:"contested"`
+	NextMove  float64 `json:"next_move"`
+}
+
+type KingOfTheHillState struct {
+	Mode          string         `json:"mode"`
+	Phase         string         `json:"phase"`
+	TimeRemaining float64        `json:"time_remaining"`
+	Zone          CaptureZone    `json:"zone"`
+	Points        map[string]int `json:"points"`
+	ScoreToWin    int            `json:"score_to_win"`
+	WinnerID      string         `json:"winner_id,omitempty"`
+}
+
+type KingOfTheHill struct {
+	World  *World
+	Config KingOfTheHillConfig
+
+	Phase         string
+	TimeRemaining float64
+	Zone          CaptureZone
+	Points        map[string]int
+	WinnerID      string
+
+	pointTick float64
+	onEvent   func(eventType string, data any)
+}
+
+func DefaultKingOfTheHillConfig() KingOfTheHillConfig {
+	return KingOfTheHillConfig{
+		ZoneRadius:      300,
+		MoveInterval:    60,
+		PointsPerSecond: 1,
+		ScoreToWin:      200,
+		TimeLimit:       600,
+		ResultsTime:     15,
+	}
+}
+
+func NewKingOfTheHill(world *World, config KingOfTheHillConfig) *KingOfTheHill {
+	k := &KingOfTheHill{
+		World:  world,
+		Config: config,
+		Points: make(map[string]int),
+	}
+	k.start()
+	return k
+}
+
+func (k *KingOfTheHill) SetEventHandler(handler func(eventType string, data any)) {
+	k.onEvent = handler
+}
+
+func (k *KingOfTheHill) State() any {
+	return k.state()
+}
+
+func (k *KingOfTheHill) state() KingOfTheHillState {
+	points := make(map[string]int, len(k.Points))
+	for side, value := range k.Points {
+		points[side] = value
+	}
+
+	return KingOfTheHillState{
+		Mode:          "koth",
+		Phase:         k.Phase,
+		TimeRemaining: k.TimeRemaining,
+		Zone:          k.Zone,
+		Points:        points,
+		ScoreToWin:    k.Config.ScoreToWin,
+		WinnerID:      k.WinnerID,
+	}
+}
+
+func (k *KingOfTheHill) Update(deltaTime float64) {
+	k.World.Update(deltaTime)
+
+	k.World.Mu.Lock()
+	defer k.World.Mu.Unlock()
+
+	k.TimeRemaining -= deltaTime
+
+	switch k.Phase {
+	case "running":
+		k.updateZone(deltaTime)
+		if winner := k.leader(); winner != "" && k.Points[winner] >= k.Config.ScoreToWin {
+			k.finish(winner)
+		} else if k.TimeRemaining <= 0 {
+			k.finish(winner)
+		}
+
+	case "finished":
+		if k.TimeRemaining <= 0 {
+			k.World.resetLocked()
+			k.start()
+		}
+	}
+}
+
+func (k *KingOfTheHill) updateZone(deltaTime float64) {
+	zone := &k.Zone
+
+	zone.NextMove -= deltaTime
+	if zone.NextMove <= 0 {
+		k.moveZone()
+		k.emit("koth_update")
+	}
+
+	sides := make(map[string]bool)
+	occupants := make([]*Player, 0)
+	for _, p := range k.World.Players {
+		if !p.IsAlive() {
+			continue
+		}
+
+		dx := p.X - zone.X
+		dy := p.Y - zone.Y
+		if dx*dx+dy*dy > zone.Radius*zone.Radius {
+			continue
+		}
+
+		sides[k.sideOf(p)] = true
+		occupants = append(occupants, p)
+	}
+
+	previousOwner := zone.OwnerID
+	previousContested := zone.Contested
+
+	zone.Contested = len(sides) > 1
+	if len(sides) == 1 {
+		for side := range sides {
+			zone.OwnerID = side
+		}
+	}
+
+	if zone.OwnerID != previousOwner || zone.Contested != previousContested {
+		k.emit("koth_update")
+	}
+
+	if len(sides) != 1 {
+		k.pointTick = 0
+		return
+	}
+
+	k.pointTick += deltaTime
+	if k.pointTick < 1.0 {
+		return
+	}
+	k.pointTick -= 1.0
+
+	k.Points[zone.OwnerID] += k.Config.PointsPerSecond
+	for _, p := range occupants {
+		p.Score += k.Config.PointsPerSecond
+	}
+}
+
+func (k *KingOfTheHill) moveZone() {
+	radius := k.Config.ZoneRadius
+	limit := k.World.WorldSize/2 - radius
+
+	x, y := k.Zone.X, k.Zone.Y
+	for attempt := 0; attempt < 20; attempt++ {
+		x, y = k.World.Map.randomPoint()
+		x = math.Max(-limit, math.Min(limit, x))
+		y = math.Max(-limit, math.Min(limit, y))
+		if !k.World.Map.Blocks(x, y, radius/2) {
+			break
+		}
+	}
+
+	k.Zone = CaptureZone{
+		X:        x,
+		Y:        y,
+		Radius:   radius,
+		NextMove: k.Config.MoveInterval,
+	}
+	k.pointTick = 0
+}
+
+func (k *KingOfTheHill) sideOf(p *Player) string {
+	if p.TeamID != "" {
+		return p.TeamID
+	}
+	return p.ID
+}
+
+func (k *KingOfTheHill) leader() string {
+	sides := make([]string, 0, len(k.Points))
+	for side := range k.Points {
+		sides = append(sides, side)
+	}
+	if len(sides) == 0 {
+		return ""
+	}
+
+	sort.Slice(sides, func(i, j int) bool {
+		if k.Points[sides[i]] != k.Points[sides[j]] {
+			return k.Points[sides[i]] > k.Points[sides[j]]
+		}
+		return sides[i] < sides[j]
+	})
+
+	if len(sides) > 1 && k.Points[sides[0]] == k.Points[sides[1]] {
+		return ""
+	}
+	return sides[0]
+}
+
+func (k *KingOfTheHill) start() {
+	k.Phase = "running"
+	k.TimeRemaining = k.Config.TimeLimit
+	k.WinnerID = ""
+	k.Points = make(map[string]int)
+	k.moveZone()
+	k.emit("match_phase")
+}
+
+func (k *KingOfTheHill) finish(winner string) {
+	k.Phase = "finished"
+	k.TimeRemaining = k.Config.ResultsTime
+	k.WinnerID = winner
+	k.emit("match_phase")
+}
+
+func (k *KingOfTheHill) emit(eventType string) {
+	if k.onEvent != nil {
+		k.onEvent(eventType, k.state())
+	}
+}
@@ -0,0 +1,34 @@
+package game
+
+import "testing"
+
+func TestKingOfTheHillTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		points     map[string]int
+		wantWinner string
+	}{
+		{"tied sides draw", map[string]int{"blue": 40, "red": 40}, ""},
+		{"tie below the leader does not matter", map[string]int{"a": 50, "b": 40, "c": 40}, "a"},
+		{"no points draw", map[string]int{}, ""},
+		{"leader wins", map[string]int{"blue": 39, "red": 40}, "red"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultKingOfTheHillConfig()
+			config.TimeLimit = 1
+			k := NewKingOfTheHill(NewWorld(NewEmptyMap(2000)), config)
+			k.Points = tt.points
+
+			k.Update(2)
+
+			if k.Phase != "finished" {
+				t.Fatalf("phase = %s, want finished", k.Phase)
+			}
+			if k.WinnerID != tt.wantWinner {
+				t.Fatalf("winner = %q, want %q", k.WinnerID, tt.wantWinner)
+			}
+		})
+	}
+}
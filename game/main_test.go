@@ -0,0 +1,13 @@
+package game
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if err := LoadCards("../cards.json"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,301 @@
+package game
+
+import (
+	"math"
+	"sort"
+)
+
+type ZoneStage struct {
+	Wait   float64 `json:"wait"`
+	Shrink float64 `json:"shrink"`
+	Radius float64 `json:"radius"`
+}
+
+type SafeZone struct {
+	X            float64 `json:"x"`
+	Y            float64 `json:"y"`
+	Radius       float64 `json:"radius"`
+	TargetRadius float64 `json:"target_radius"`
+	Damage       int     `json:"damage"`
+
+	stages     []ZoneStage
+	stage      int
+	stageTime  float64
+	fromRadius float64
+	damageTick float64
+}
+
+type MatchConfig struct {
+	MinPlayers    int
+	CountdownTime float64
+	ResultsTime   float64
+	ZoneDamage    int
+	ZoneStages    []ZoneStage
+}
+
+type MatchResult struct {
+	PlayerID  string `json:"player_id"`
+	Placement int    `json:"placement"`
+	Score     int    `json:"score"`
+	Kills     int    `json:"kills"`
+}
+
+type MatchState struct {
+	Mode          string        `json:"mode"`
+	Phase         string        `json:"phase"`
+	TimeRemaining float64       `json:"time_remaining"`
+	Alive         int           `json:"alive"`
+	SafeZone      *SafeZone     `json:"safe_zone,omitempty"`
+	WinnerID      string        `json:"winner_id,omitempty"`
+	Results       []MatchResult `json:"results,omitempty"`
+}
+
+type Match struct {
+	World  *World
+	Config MatchConfig
+
+	Phase         string
+	TimeRemaining float64
+	WinnerID      string
+	Results       []MatchResult
+
+	onEvent func(eventType string, data any)
+}
+
+func DefaultMatchConfig(worldSize float64) MatchConfig {
+	return MatchConfig{
+		MinPlayers:    2,
+		CountdownTime: 10,
+		ResultsTime:   15,
+		ZoneDamage:    8,
+		ZoneStages: []ZoneStage{
+			{Wait: 60, Shrink: 45, Radius: worldSize * 0.35},
+			{Wait: 45, Shrink: 40, Radius: worldSize * 0.2},
+			{Wait: 30, Shrink: 30, Radius: worldSize * 0.08},
+			{Wait: 20, Shrink: 30, Radius: 0},
+		},
+	}
+}
+
+func NewSafeZone(x, y, radius float64, damage int, stages []ZoneStage) *SafeZone {
+	zone := &SafeZone{
+		X:            x,
+		Y:            y,
+		Radius:       radius,
+		TargetRadius: radius,
+		Damage:       damage,
+		stages:       stages,
+		fromRadius:   radius,
+	}
+	if len(stages) > 0 {
+		zone.TargetRadius = stages[0].Radius
+	}
+	return zone
+}
+
+func (z *SafeZone) Update(deltaTime float64) {
+	if z.stage >= len(z.stages) {
+		return
+	}
+
+	stage := z.stages[z.stage]
+	z.stageTime += deltaTime
+
+	if z.stageTime > stage.Wait {
+		progress := 1.0
+		if stage.Shrink > 0 {
+			progress = math.Min(1, (z.stageTime-stage.Wait)/stage.Shrink)
+		}
+		z.Radius = z.fromRadius + (stage.Radius-z.fromRadius)*progress
+	}
+
+	if z.stageTime >= stage.Wait+stage.Shrink {
+		z.stage++
+		z.stageTime = 0
+		z.fromRadius = z.Radius
+		if z.stage < len(z.stages) {
+			z.TargetRadius = z.stages[z.stage].Radius
+		}
+	}
+}
+
+func (z *SafeZone) Contains(p *Player) bool {
+	dx := p.X - z.X
+	dy := p.Y - z.Y
+	return dx*dx+dy*dy <= z.Radius*z.Radius
+}
+
+func (z *SafeZone) timeToNextStage() float64 {
+	if z.stage >= len(z.stages) {
+		return 0
+	}
+
+	stage := z.stages[z.stage]
+	return stage.Wait + stage.Shrink - z.stageTime
+}
+
+func (w *World) updateSafeZone(deltaTime float64) {
+	zone := w.SafeZone
+	if zone == nil {
+		return
+	}
+
+	zone.Update(deltaTime)
+
+	zone.damageTick += deltaTime
+	if zone.damageTick < 1.0 {
+		return
+	}
+	zone.damageTick -= 1.0
+
+	for _, player := range w.playerSlice {
+		if !player.IsAlive() || zone.Contains(player) {
+			continue
+		}
+		if player.TakeDamage(zone.Damage) {
+			w.handlePlayerDeath(player, nil)
+		}
+	}
+}
+
+func NewMatch(world *World, config MatchConfig) *Match {
+	return &Match{
+		World:  world,
+		Config: config,
+		Phase:  "lobby",
+	}
+}
+
+func (m *Match) Update(deltaTime float64) {
+	m.World.Update(deltaTime)
+
+	m.World.Mu.Lock()
+	defer m.World.Mu.Unlock()
+
+	switch m.Phase {
+	case "lobby":
+		if len(m.World.Players) >= m.Config.MinPlayers {
+			m.setPhase("countdown", m.Config.CountdownTime)
+		}
+
+	case "countdown":
+		if len(m.World.Players) < m.Config.MinPlayers {
+			m.setPhase("lobby", 0)
+			return
+		}
+
+		m.TimeRemaining -= deltaTime
+		if m.TimeRemaining <= 0 {
+			m.start()
+		}
+
+	case "running":
+		if m.World.SafeZone != nil {
+			m.TimeRemaining = m.World.SafeZone.timeToNextStage()
+		}
+		if m.aliveCount() <= 1 {
+			m.finish()
+		}
+
+	case "finished":
+		m.TimeRemaining -= deltaTime
+		if m.TimeRemaining <= 0 {
+			m.World.RespawnEnabled = true
+			m.World.resetLocked()
+			m.WinnerID = ""
+			m.Results = nil
+			m.setPhase("lobby", 0)
+		}
+	}
+}
+
+func (m *Match) SetEventHandler(handler func(eventType string, data any)) {
+	m.onEvent = handler
+}
+
+func (m *Match) State() any {
+	return m.state()
+}
+
+func (m *Match) state() MatchState {
+	var safeZone *SafeZone
+	if m.World.SafeZone != nil {
+		zone := *m.World.SafeZone
+		safeZone = &zone
+	}
+
+	return MatchState{
+		Mode:          "br",
+		Phase:         m.Phase,
+		TimeRemaining: m.TimeRemaining,
+		Alive:         m.aliveCount(),
+		SafeZone:      safeZone,
+		WinnerID:      m.WinnerID,
+		Results:       m.Results,
+	}
+}
+
+func (m *Match) start() {
+	w := m.World
+	w.RespawnEnabled = false
+	w.resetLocked()
+	w.SafeZone = NewSafeZone(0, 0, w.WorldSize/2*math.Sqrt2, m.Config.ZoneDamage, m.Config.ZoneStages)
+
+	m.setPhase("running", 0)
+}
+
+func (m *Match) finish() {
+	results := make([]MatchResult, 0, len(m.World.Players))
+	for _, p := range m.World.Players {
+		results = append(results, MatchResult{
+			PlayerID:  p.ID,
+			Placement: 0,
+			Score:     p.Score,
+			Kills:     p.Kills,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		pi := m.World.Players[results[i].PlayerID]
+		pj := m.World.Players[results[j].PlayerID]
+		if pi.IsAlive() != pj.IsAlive() {
+			return pi.IsAlive()
+		}
+		if pi.EliminationOrder != pj.EliminationOrder {
+			return pi.EliminationOrder > pj.EliminationOrder
+		}
+		return pi.Score > pj.Score
+	})
+
+	for i := range results {
+		results[i].Placement = i + 1
+	}
+
+	m.Results = results
+	m.WinnerID = ""
+	if len(results) > 0 {
+		m.WinnerID = results[0].PlayerID
+	}
+
+	m.World.SafeZone = nil
+	m.setPhase("finished", m.Config.ResultsTime)
+}
+
+func (m *Match) setPhase(phase string, duration float64) {
+	m.Phase = phase
+	m.TimeRemaining = duration
+
+	if m.onEvent != nil {
+		m.onEvent("match_phase", m.state())
+	}
+}
+
+func (m *Match) aliveCount() int {
+	alive := 0
+	for _, p := range m.World.Players {
+		if p.IsAlive() {
+			alive++
+		}
+	}
+	return alive
+}
@@ -0,0 +1,7 @@
+package game
+
+type Mode interface {
+	Update(deltaTime float64)
+	State() any
+	SetEventHandler(handler func(eventType string, data any))
+}
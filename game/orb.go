@@ -0,0 +1,172 @@
+package game
+
+type TeamBase struct {
+	TeamID string  `json:"team"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+}
+
+type FlagOrb struct {
+	TeamID      string  `json:"team"`
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	Size        float64 `json:"size"`
+	CarrierID   string  `json:"carrier_id,omitempty"`
+	AtHome      bool    `json:"at_home"`
+	ReturnTimer float64 `json:"return_timer,omitempty"`
+
+	homeX float64
+	homeY float64
+}
+
+type OrbEvent struct {
+	Type     string `json:"type"`
+	TeamID   string `json:"team"`
+	PlayerID string `json:"player_id,omitempty"`
+}
+
+const (
+	orbCarrierSlow  = 0.35
+	orbAutoReturn   = 30.0
+	orbPickupRadius = 20.0
+)
+
+func NewFlagOrb(base TeamBase) *FlagOrb {
+	return &FlagOrb{
+		TeamID: base.TeamID,
+		X:      base.X,
+		Y:      base.Y,
+		Size:   orbPickupRadius,
+		AtHome: true,
+		homeX:  base.X,
+		homeY:  base.Y,
+	}
+}
+
+func (o *FlagOrb) ReturnHome() {
+	o.X = o.homeX
+	o.Y = o.homeY
+	o.CarrierID = ""
+	o.AtHome = true
+	o.ReturnTimer = 0
+}
+
+func (o *FlagOrb) Drop(x, y float64) {
+	o.X = x
+	o.Y = y
+	o.CarrierID = ""
+	o.AtHome = false
+	o.ReturnTimer = orbAutoReturn
+}
+
+func (o *FlagOrb) Touches(p *Player) bool {
+	dx := p.X - o.X
+	dy := p.Y - o.Y
+	reach := o.Size + float64(p.Size)
+	return dx*dx+dy*dy <= reach*reach
+}
+
+func (b *TeamBase) Contains(p *Player) bool {
+	dx := p.X - b.X
+	dy := p.Y - b.Y
+	return dx*dx+dy*dy <= b.Radius*b.Radius
+}
+
+func (w *World) setupOrbs() {
+	w.Bases = w.Bases[:0]
+	w.Orbs = w.Orbs[:0]
+	w.orbEvents = w.orbEvents[:0]
+
+	for _, base := range w.Map.Bases {
+		b := base
+		w.Bases = append(w.Bases, &b)
+		w.Orbs = append(w.Orbs, NewFlagOrb(base))
+	}
+}
+
+func (w *World) dropOrbs(carrier *Player) {
+	for _, orb := range w.Orbs {
+		if orb.CarrierID == carrier.ID {
+			orb.Drop(carrier.X, carrier.Y)
+			w.orbEvents = append(w.orbEvents, OrbEvent{Type: "drop", TeamID: orb.TeamID, PlayerID: carrier.ID})
+		}
+	}
+}
+
+func (w *World) baseOf(teamID string) *TeamBase {
+	for _, base := range w.Bases {
+		if base.TeamID == teamID {
+			return base
+		}
+	}
+	return nil
+}
+
+func (w *World) orbOf(teamID string) *FlagOrb {
+	for _, orb := range w.Orbs {
+		if orb.TeamID == teamID {
+			return orb
+		}
+	}
+	return nil
+}
+
+func (w *World) updateOrbs(deltaTime float64) []OrbEvent {
+	events := append(make([]OrbEvent, 0, len(w.orbEvents)), w.orbEvents...)
+	w.orbEvents = w.orbEvents[:0]
+
+	for _, orb := range w.Orbs {
+		if orb.CarrierID != "" {
+			carrier, ok := w.Players[orb.CarrierID]
+			if !ok || !carrier.IsAlive() {
+				events = append(events, OrbEvent{Type: "drop", TeamID: orb.TeamID, PlayerID: orb.CarrierID})
+				orb.Drop(orb.X, orb.Y)
+				continue
+			}
+
+			orb.X = carrier.X
+			orb.Y = carrier.Y
+			carrier.ApplySlow(orbCarrierSlow, 0.25)
+
+			base := w.baseOf(carrier.TeamID)
+			ownOrb := w.orbOf(carrier.TeamID)
+			if base != nil && base.Contains(carrier) && (ownOrb == nil || ownOrb.AtHome) {
+				orb.ReturnHome()
+				events = append(events, OrbEvent{Type: "capture", TeamID: carrier.TeamID, PlayerID: carrier.ID})
+			}
+			continue
+		}
+
+		if !orb.AtHome {
+			orb.ReturnTimer -= deltaTime
+			if orb.ReturnTimer <= 0 {
+				orb.ReturnHome()
+				events = append(events, OrbEvent{Type: "return", TeamID: orb.TeamID})
+				continue
+			}
+		}
+
+		for _, p := range w.playerSlice {
+			if !p.IsAlive() || p.TeamID == "" || !orb.Touches(p) {
+				continue
+			}
+
+			if p.TeamID != orb.TeamID {
+				orb.CarrierID = p.ID
+				orb.AtHome = false
+				orb.ReturnTimer = 0
+				events = append(events, OrbEvent{Type: "pickup", TeamID: orb.TeamID, PlayerID: p.ID})
+				break
+			}
+
+			if !orb.AtHome {
+				orb.ReturnHome()
+				events = append(events, OrbEvent{Type: "return", TeamID: orb.TeamID, PlayerID: p.ID})
+				break
+			}
+		}
+	}
+
+	return events
+}
@@ -1,23 +1,113 @@
 package game
 
 import (
+	"math"
+
 	"github.com/google/uuid"
 )
 
 type Pellet struct {
-	ID    string
-	X     float64
-	Y     float64
-	Size  float64
-	Value int
+	ID          string
+	Type        string
+	X           float64
+	Y           float64
+	Size        float64
+	Value       int
+	Lifetime    float64
+	MaxLifetime float64
+}
+
+var pelletSpawnChances = []struct {
+	Type   string
+	Chance float64
+}{
+	{Type: "golden", Chance: 0.01},
+	{Type: "speed", Chance: 0.002},
+	{Type: "invulnerable", Chance: 0.001},
+	{Type: "double_score", Chance: 0.002},
+}
+
+var powerUpDurations = map[string]float64{
+	"speed":        8.0,
+	"invulnerable": 4.0,
+	"double_score": 15.0,
 }
 
 func NewPellet(x, y float64) *Pellet {
 	return &Pellet{
 		ID:    uuid.New().String(),
+		Type:  "normal",
 		X:     x,
 		Y:     y,
 		Size:  4,
 		Value: 1,
 	}
 }
+
+func NewGoldenPellet(x, y float64) *Pellet {
+	pellet := NewPellet(x, y)
+	pellet.Type = "golden"
+	pellet.Size = 8
+	pellet.Value = 15
+	return pellet
+}
+
+func NewPowerUpPellet(x, y float64, powerUp string) *Pellet {
+	pellet := NewPellet(x, y)
+	pellet.Type = powerUp
+	pellet.Size = 10
+	pellet.Value = 0
+	return pellet
+}
+
+func NewRemainsPellet(x, y float64, value int, lifetime float64) *Pellet {
+	pellet := NewPellet(x, y)
+	pellet.Type = "remains"
+	pellet.Size = 4 + math.Sqrt(float64(value))
+	pellet.Value = value
+	pellet.Lifetime = lifetime
+	pellet.MaxLifetime = lifetime
+	return pellet
+}
+
+func RandomPelletType(roll float64) string {
+	for _, spawn := range pelletSpawnChances {
+		if roll < spawn.Chance {
+			return spawn.Type
+		}
+		roll -= spawn.Chance
+	}
+	return "normal"
+}
+
+func (p *Pellet) Decays() bool {
+	return p.MaxLifetime > 0
+}
+
+func (p *Pellet) CurrentValue() int {
+	if !p.Decays() {
+		return p.Value
+	}
+
+	value := int(float64(p.Value) * p.Lifetime / p.MaxLifetime)
+	if value < 1 {
+		value = 1
+	}
+	return value
+}
+
+func (p *Player) EatPellet(pellet *Pellet) {
+	value := pellet.CurrentValue()
+	if p.HasEffect("double_score") {
+		value *= 2
+	}
+
+	p.Score += value
+	if p.Health < p.MaxHealth {
+		p.Heal(value)
+	}
+
+	if duration, ok := powerUpDurations[pellet.Type]; ok {
+		p.AddEffect(pellet.Type, 0, duration, duration, "pellet")
+	}
+}
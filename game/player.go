@@ -1,9 +1,14 @@
 package game
 
-import "math"
+import (
+	"math"
+	"math/rand"
+	"slices"
+)
 
 type Player struct {
-	ID string
+	ID     string
+	TeamID string
 
 	X         float64
 	Y         float64
@@ -30,6 +35,15 @@ type Player struct {
 	NextCardScore int
 	AppliedCards  []string
 	CardsPending  bool
+	OfferedCards  []uint64
+	BanishedCards []string
+	Rerolls       int
+
+	CardOfferRemaining float64
+
+	CardSeed        int64
+	CardRNG         *rand.Rand
+	OffersSincePity int
 
 	Input PlayerInput
 
@@ -41,6 +55,25 @@ type Player struct {
 
 	SlowEffect   float64
 	SlowDuration float64
+
+	Ability          string
+	AbilityCooldown  float64
+	AbilityRequested bool
+
+	BurstBarrierAmount    int
+	BurstBarrierRemaining float64
+
+	AutoAttacks []AutoAttack
+
+	Kills            int
+	Eliminated       bool
+	Latency          int
+	EliminationOrder int
+
+	Emote          string
+	EmoteRemaining float64
+	Emotes         []string
+	Achievements   []string
 }
 
 type PlayerInput struct {
@@ -68,7 +101,16 @@ type ActiveEffect struct {
 	SourceID  string
 }
 
-func NewPlayer(id string, x, y float64) *Player {
+var effectStacking = map[string]string{
+	"poison":       "refresh",
+	"burn":         "refresh",
+	"regen":        "max",
+	"speed":        "max",
+	"invulnerable": "max",
+	"double_score": "max",
+}
+
+func NewPlayer(id string, x, y float64, cardSeed int64) *Player {
 	return &Player{
 		ID:                  id,
 		X:                   x,
@@ -90,12 +132,21 @@ func NewPlayer(id string, x, y float64) *Player {
 		NextCardScore:       10,
 		AppliedCards:        make([]string, 0, 10),
 		CardsPending:        false,
+		OfferedCards:        make([]uint64, 0, 3),
+		BanishedCards:       make([]string, 0),
+		Rerolls:             0,
+		CardSeed:            cardSeed,
+		CardRNG:             rand.New(rand.NewSource(cardSeed)),
+		OffersSincePity:     0,
 		CollisionCooldown:   0,
 		Auras:               []Aura{},
+		AutoAttacks:         []AutoAttack{},
 		ActiveEffects:       []ActiveEffect{},
 		SetBonuses:          make(map[string]int),
 		SlowEffect:          0,
 		SlowDuration:        0,
+		Emotes:              slices.Clone(baseEmotes),
+		Achievements:        []string{},
 	}
 }
 
@@ -107,6 +158,29 @@ func (p *Player) Update(deltaTime float64) {
 		}
 	}
 
+	if p.EmoteRemaining > 0 {
+		p.EmoteRemaining -= deltaTime
+		if p.EmoteRemaining <= 0 {
+			p.EmoteRemaining = 0
+			p.Emote = ""
+		}
+	}
+
+	if p.AbilityCooldown > 0 {
+		p.AbilityCooldown -= deltaTime
+		if p.AbilityCooldown < 0 {
+			p.AbilityCooldown = 0
+		}
+	}
+
+	if p.BurstBarrierRemaining > 0 {
+		p.BurstBarrierRemaining -= deltaTime
+		if p.BurstBarrierRemaining <= 0 {
+			p.BurstBarrierAmount = 0
+			p.BurstBarrierRemaining = 0
+		}
+	}
+
 	if p.SlowDuration > 0 {
 		p.SlowDuration -= deltaTime
 		if p.SlowDuration <= 0 {
@@ -115,6 +189,10 @@ func (p *Player) Update(deltaTime float64) {
 		}
 	}
 
+	if p.CardsPending && p.CardOfferRemaining > 0 {
+		p.CardOfferRemaining -= deltaTime
+	}
+
 	p.UpdateActiveEffects(deltaTime)
 
 	if p.Barrier < p.MaxBarrier && p.BarrierRegen > 0 {
@@ -149,6 +227,9 @@ func (p *Player) Update(deltaTime float64) {
 	targetVelY := 0.0
 
 	effectiveSpeed := float64(p.Speed) * (1.0 - p.SlowEffect)
+	if p.HasEffect("speed") {
+		effectiveSpeed *= 1.5
+	}
 
 	if p.Input.W {
 		targetVelY -= effectiveSpeed
@@ -182,8 +263,15 @@ func (p *Player) Update(deltaTime float64) {
 	p.Y += p.VelocityY
 }
 
-func (p *Player) UpdateAuras(deltaTime float64, nearbyPlayers []*Player) {
+func (p *Player) UpdateAuras(deltaTime float64, nearbyPlayers []*Player, pellets map[string]*Pellet, friendlyFire bool) []*Player {
+	var killed []*Player
+
 	for i := range p.Auras {
+		if p.Auras[i].Type == "magnet" {
+			p.AttractPellets(&p.Auras[i], pellets, deltaTime)
+			continue
+		}
+
 		p.Auras[i].LastTick += deltaTime
 
 		if p.Auras[i].LastTick >= p.Auras[i].TickRate {
@@ -192,7 +280,16 @@ func (p *Player) UpdateAuras(deltaTime float64, nearbyPlayers []*Player) {
 			radiusSq := (p.Auras[i].Radius + float64(p.Size)) * (p.Auras[i].Radius + float64(p.Size))
 
 			for _, target := range nearbyPlayers {
-				if target.ID == p.ID {
+				if target.ID == p.ID || !target.IsAlive() {
+					continue
+				}
+
+				isAlly := p.IsAllyOf(target)
+				if p.Auras[i].Type == "heal_allies" {
+					if !isAlly {
+						continue
+					}
+				} else if isAlly && !friendlyFire {
 					continue
 				}
 
@@ -200,55 +297,170 @@ func (p *Player) UpdateAuras(deltaTime float64, nearbyPlayers []*Player) {
 				dy := p.Y - target.Y
 				distanceSq := dx*dx + dy*dy
 
-				if distanceSq <= radiusSq {
-					p.ApplyAuraEffect(&p.Auras[i], target)
+				if distanceSq <= radiusSq && p.ApplyAuraEffect(&p.Auras[i], target) {
+					killed = append(killed, target)
 				}
 			}
 		}
 	}
+
+	return killed
 }
 
-func (p *Player) ApplyAuraEffect(aura *Aura, target *Player) {
+func (p *Player) ApplyAuraEffect(aura *Aura, target *Player) bool {
 	switch aura.Type {
 	case "damage":
-		target.TakeDamage(aura.Strength)
+		return target.TakeDamage(aura.Strength)
 	case "slow":
-		strength := float64(aura.Strength) / 100.0
-		if target.SlowEffect < strength {
-			target.SlowEffect = strength
-			target.SlowDuration = aura.TickRate * 2
-		}
+		target.ApplySlow(float64(aura.Strength)/100.0, aura.TickRate*2)
 	case "poison":
-		target.AddDoT("poison", aura.Strength, 3.0, p.ID)
+		target.AddEffect("poison", aura.Strength, 3.0, 1.0, p.ID)
+	case "burn":
+		target.AddEffect("burn", aura.Strength, 4.0, 1.0, p.ID)
 	case "lifesteal":
-		target.TakeDamage(aura.Strength)
+		died := target.TakeDamage(aura.Strength)
 		p.Health += aura.Strength
 		if p.Health > p.MaxHealth {
 			p.Health = p.MaxHealth
 		}
+		return died
+	case "knockback":
+		p.PushPlayer(target, float64(aura.Strength))
+	case "pull":
+		p.PushPlayer(target, -float64(aura.Strength))
+	case "heal_allies":
+		target.Heal(aura.Strength)
+	}
+	return false
+}
+
+func (p *Player) IsAllyOf(other *Player) bool {
+	return p.TeamID != "" && p.TeamID == other.TeamID
+}
+
+func (p *Player) PushPlayer(target *Player, impulse float64) {
+	dx := target.X - p.X
+	dy := target.Y - p.Y
+	distance := math.Sqrt(dx*dx + dy*dy)
+	if distance == 0 {
+		return
+	}
+
+	target.VelocityX += dx / distance * impulse
+	target.VelocityY += dy / distance * impulse
+}
+
+func (p *Player) AttractPellets(aura *Aura, pellets map[string]*Pellet, deltaTime float64) {
+	radius := aura.Radius + float64(p.Size)
+	radiusSq := radius * radius
+	step := float64(aura.Strength) * deltaTime
+
+	for _, pellet := range pellets {
+		dx := p.X - pellet.X
+		dy := p.Y - pellet.Y
+		distanceSq := dx*dx + dy*dy
+		if distanceSq > radiusSq || distanceSq == 0 {
+			continue
+		}
+
+		distance := math.Sqrt(distanceSq)
+		if step >= distance {
+			pellet.X = p.X
+			pellet.Y = p.Y
+			continue
+		}
+
+		pellet.X += dx / distance * step
+		pellet.Y += dy / distance * step
+	}
+}
+
+func (p *Player) Heal(amount int) {
+	p.Health += amount
+	if p.Health > p.MaxHealth {
+		p.Health = p.MaxHealth
 	}
 }
 
-func (p *Player) AddDoT(dotType string, strength int, duration float64, sourceID string) {
+func (p *Player) AddEffect(effectType string, strength int, duration float64, tickRate float64, sourceID string) {
+	if tickRate <= 0 {
+		tickRate = 1.0
+	}
+
+	switch effectStacking[effectType] {
+	case "max":
+		for i := range p.ActiveEffects {
+			effect := &p.ActiveEffects[i]
+			if effect.Type != effectType {
+				continue
+			}
+
+			if strength > effect.Strength {
+				effect.Strength = strength
+				effect.TickRate = tickRate
+				effect.SourceID = sourceID
+			}
+			if duration > effect.Remaining {
+				effect.Duration = duration
+				effect.Remaining = duration
+			}
+			return
+		}
+		p.appendEffect(effectType, strength, duration, tickRate, sourceID)
+	default:
+		p.RefreshEffect(effectType, strength, duration, tickRate, sourceID)
+	}
+}
+
+func (p *Player) RefreshEffect(effectType string, strength int, duration float64, tickRate float64, sourceID string) {
+	if tickRate <= 0 {
+		tickRate = 1.0
+	}
+
 	for i := range p.ActiveEffects {
-		if p.ActiveEffects[i].Type == dotType && p.ActiveEffects[i].SourceID == sourceID {
-			p.ActiveEffects[i].Remaining = duration
-			p.ActiveEffects[i].Strength = strength
+		effect := &p.ActiveEffects[i]
+		if effect.Type == effectType && effect.SourceID == sourceID {
+			effect.Duration = duration
+			effect.Remaining = duration
+			effect.Strength = strength
+			effect.TickRate = tickRate
 			return
 		}
 	}
 
+	p.appendEffect(effectType, strength, duration, tickRate, sourceID)
+}
+
+func (p *Player) appendEffect(effectType string, strength int, duration float64, tickRate float64, sourceID string) {
 	p.ActiveEffects = append(p.ActiveEffects, ActiveEffect{
-		Type:      dotType,
+		Type:      effectType,
 		Strength:  strength,
 		Duration:  duration,
 		Remaining: duration,
-		TickRate:  1.0,
+		TickRate:  tickRate,
 		LastTick:  0,
 		SourceID:  sourceID,
 	})
 }
 
+func (p *Player) ApplySlow(strength float64, duration float64) {
+	if p.SlowEffect < strength {
+		p.SlowEffect = strength
+		p.SlowDuration = duration
+	} else if p.SlowEffect == strength && p.SlowDuration < duration {
+		p.SlowDuration = duration
+	}
+}
+
+func (p *Player) HasEffect(effectType string) bool {
+	for i := range p.ActiveEffects {
+		if p.ActiveEffects[i].Type == effectType {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Player) UpdateActiveEffects(deltaTime float64) {
 	i := 0
 	for i < len(p.ActiveEffects) {
@@ -271,10 +483,7 @@ func (p *Player) UpdateActiveEffects(deltaTime float64) {
 			case "burn":
 				p.TakeDamage(effect.Strength)
 			case "regen":
-				p.Health += effect.Strength
-				if p.Health > p.MaxHealth {
-					p.Health = p.MaxHealth
-				}
+				p.Heal(effect.Strength)
 			}
 		}
 		i++
@@ -282,7 +491,7 @@ func (p *Player) UpdateActiveEffects(deltaTime float64) {
 }
 
 func (p *Player) ShouldOfferCards() bool {
-	return p.Score >= p.NextCardScore && !p.CardsPending
+	return p.IsAlive() && p.Score >= p.NextCardScore && !p.CardsPending
 }
 
 func (p *Player) UpdateNextCardScore() {
@@ -292,6 +501,36 @@ func (p *Player) UpdateNextCardScore() {
 		p.NextCardScore += 100
 	}
 	p.CardsPending = false
+	p.OfferedCards = p.OfferedCards[:0]
+	p.CardOfferRemaining = 0
+}
+
+func (p *Player) SetOfferedCards(cards []Card) {
+	p.OfferedCards = p.OfferedCards[:0]
+	for _, card := range cards {
+		p.OfferedCards = append(p.OfferedCards, card.ID)
+	}
+	p.CardOfferRemaining = cardOfferTimeout
+}
+
+func (p *Player) CardOfferExpired() bool {
+	return p.CardsPending && p.CardOfferRemaining <= 0
+}
+
+func (p *Player) IsCardOffered(cardID uint64) bool {
+	if !p.CardsPending {
+		return false
+	}
+	for _, id := range p.OfferedCards {
+		if id == cardID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Player) CanReroll() bool {
+	return p.CardsPending && p.Rerolls > 0
 }
 
 func (p *Player) SetInput(input PlayerInput) {
@@ -319,6 +558,18 @@ func (p *Player) ApplyCardEffect(effect CardEffect) {
 			TickRate: effect.AuraTick,
 			LastTick: 0,
 		})
+	case "ability":
+		p.SetAbility(effect.Ability)
+	case "auto_attack_add":
+		p.AutoAttacks = append(p.AutoAttacks, AutoAttack{
+			Damage:   int(effect.ProjectileDamage),
+			Interval: effect.ProjectileInterval,
+			Range:    effect.ProjectileRange,
+			Speed:    effect.ProjectileSpeed,
+			LastFire: 0,
+		})
+	case "effect_add":
+		p.AddEffect(effect.EffectType, int(effect.EffectStrength), effect.EffectDuration, effect.EffectTick, p.ID)
 	case "max_barrier":
 		p.MaxBarrier += int(effect.Modifier)
 		p.Barrier = p.MaxBarrier
@@ -346,6 +597,20 @@ func (p *Player) IsCollidingWith(other *Player) bool {
 }
 
 func (p *Player) TakeDamage(damage int) bool {
+	if p.HasEffect("invulnerable") {
+		return false
+	}
+
+	if p.BurstBarrierAmount > 0 {
+		if damage <= p.BurstBarrierAmount {
+			p.BurstBarrierAmount -= damage
+			return false
+		}
+		damage -= p.BurstBarrierAmount
+		p.BurstBarrierAmount = 0
+		p.BurstBarrierRemaining = 0
+	}
+
 	if p.Barrier > 0 {
 		p.TimeSinceBarrierHit = 0
 
@@ -371,8 +636,13 @@ func (p *Player) Respawn(x, y float64) {
 	p.Y = y
 	p.VelocityX = 0
 	p.VelocityY = 0
+	p.MaxHealth = 100
 	p.Health = p.MaxHealth
+	p.AbsorptionRange = 1.0
 	p.Score = 0
+	p.Kills = 0
+	p.Eliminated = false
+	p.EliminationOrder = 0
 	p.Size = 40
 	p.Speed = 5
 	p.TargetSpeed = 5
@@ -381,8 +651,14 @@ func (p *Player) Respawn(x, y float64) {
 	p.CollisionCooldown = 0
 	p.NextCardScore = 10
 	p.CardsPending = false
+	p.OfferedCards = p.OfferedCards[:0]
+	p.CardOfferRemaining = 0
+	p.BanishedCards = p.BanishedCards[:0]
+	p.Rerolls = 0
+	p.OffersSincePity = 0
 	p.AppliedCards = p.AppliedCards[:0]
 	p.Auras = p.Auras[:0]
+	p.AutoAttacks = p.AutoAttacks[:0]
 	p.ActiveEffects = p.ActiveEffects[:0]
 	p.Barrier = 0
 	p.MaxBarrier = 0
@@ -391,11 +667,28 @@ func (p *Player) Respawn(x, y float64) {
 	p.TimeSinceBarrierHit = 0
 	p.SlowEffect = 0
 	p.SlowDuration = 0
+	p.Ability = ""
+	p.AbilityCooldown = 0
+	p.Emote = ""
+	p.EmoteRemaining = 0
+	p.AbilityRequested = false
+	p.BurstBarrierAmount = 0
+	p.BurstBarrierRemaining = 0
 	for k := range p.SetBonuses {
 		delete(p.SetBonuses, k)
 	}
 }
 
+func (p *Player) Eliminate(order int) {
+	p.Eliminated = true
+	p.EliminationOrder = order
+	p.Health = 0
+	p.VelocityX = 0
+	p.VelocityY = 0
+	p.CardsPending = false
+	p.OfferedCards = p.OfferedCards[:0]
+}
+
 func (p *Player) IsAlive() bool {
 	return p.Health > 0
 }
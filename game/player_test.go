@@ -0,0 +1,352 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+type effectAdd struct {
+	effectType string
+	strength   int
+	duration   float64
+	sourceID   string
+}
+
+func TestAddEffectStacking(t *testing.T) {
+	tests := []struct {
+		name          string
+		adds          []effectAdd
+		wantCount     int
+		wantStrength  int
+		wantRemaining float64
+	}{
+		{
+			name:          "poison refreshes same source",
+			adds:          []effectAdd{{"poison", 5, 3, "a"}, {"poison", 2, 1, "a"}},
+			wantCount:     1,
+			wantStrength:  2,
+			wantRemaining: 1,
+		},
+		{
+			name:          "poison from another source adds",
+			adds:          []effectAdd{{"poison", 5, 3, "a"}, {"poison", 2, 1, "b"}},
+			wantCount:     2,
+			wantStrength:  5,
+			wantRemaining: 3,
+		},
+		{
+			name:          "burn refreshes same source",
+			adds:          []effectAdd{{"burn", 3, 2, "a"}, {"burn", 3, 4, "a"}},
+			wantCount:     1,
+			wantStrength:  3,
+			wantRemaining: 4,
+		},
+		{
+			name:          "burn from another source stacks",
+			adds:          []effectAdd{{"burn", 3, 4, "a"}, {"burn", 3, 4, "a"}, {"burn", 1, 2, "b"}},
+			wantCount:     2,
+			wantStrength:  3,
+			wantRemaining: 4,
+		},
+		{
+			name:          "regen keeps the stronger effect and longer duration",
+			adds:          []effectAdd{{"regen", 5, 2, "a"}, {"regen", 3, 6, "b"}},
+			wantCount:     1,
+			wantStrength:  5,
+			wantRemaining: 6,
+		},
+		{
+			name:          "regen upgrades strength without shortening",
+			adds:          []effectAdd{{"regen", 3, 4, "a"}, {"regen", 8, 1, "b"}},
+			wantCount:     1,
+			wantStrength:  8,
+			wantRemaining: 4,
+		},
+		{
+			name:          "invulnerable does not stack",
+			adds:          []effectAdd{{"invulnerable", 1, 2, ""}, {"invulnerable", 1, 2, ""}},
+			wantCount:     1,
+			wantStrength:  1,
+			wantRemaining: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer("p", 0, 0, 1)
+			for _, add := range tt.adds {
+				p.AddEffect(add.effectType, add.strength, add.duration, 1.0, add.sourceID)
+			}
+
+			if len(p.ActiveEffects) != tt.wantCount {
+				t.Fatalf("got %d effects, want %d", len(p.ActiveEffects), tt.wantCount)
+			}
+
+			first := p.ActiveEffects[0]
+			if first.Strength != tt.wantStrength {
+				t.Errorf("strength = %d, want %d", first.Strength, tt.wantStrength)
+			}
+			if first.Remaining != tt.wantRemaining {
+				t.Errorf("remaining = %v, want %v", first.Remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestUpdateActiveEffects(t *testing.T) {
+	tests := []struct {
+		name       string
+		health     int
+		adds       []effectAdd
+		steps      []float64
+		wantHealth int
+		wantActive int
+	}{
+		{
+			name:       "burn ticks once per second",
+			health:     100,
+			adds:       []effectAdd{{"burn", 5, 4, "a"}},
+			steps:      []float64{1, 1},
+			wantHealth: 90,
+			wantActive: 1,
+		},
+		{
+			name:       "stacked burns both tick",
+			health:     100,
+			adds:       []effectAdd{{"burn", 5, 4, "a"}, {"burn", 3, 4, "b"}},
+			steps:      []float64{1},
+			wantHealth: 92,
+			wantActive: 2,
+		},
+		{
+			name:       "poison waits for its tick",
+			health:     100,
+			adds:       []effectAdd{{"poison", 4, 3, "a"}},
+			steps:      []float64{0.5, 0.4},
+			wantHealth: 100,
+			wantActive: 1,
+		},
+		{
+			name:       "poison expires without a final tick",
+			health:     100,
+			adds:       []effectAdd{{"poison", 4, 1.5, "a"}},
+			steps:      []float64{1, 1},
+			wantHealth: 96,
+			wantActive: 0,
+		},
+		{
+			name:       "regen heals",
+			health:     50,
+			adds:       []effectAdd{{"regen", 10, 5, "a"}},
+			steps:      []float64{1, 1},
+			wantHealth: 70,
+			wantActive: 1,
+		},
+		{
+			name:       "regen caps at max health",
+			health:     95,
+			adds:       []effectAdd{{"regen", 10, 5, "a"}},
+			steps:      []float64{1},
+			wantHealth: 100,
+			wantActive: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer("p", 0, 0, 1)
+			p.Health = tt.health
+			for _, add := range tt.adds {
+				p.AddEffect(add.effectType, add.strength, add.duration, 1.0, add.sourceID)
+			}
+
+			for _, dt := range tt.steps {
+				p.UpdateActiveEffects(dt)
+			}
+
+			if p.Health != tt.wantHealth {
+				t.Errorf("health = %d, want %d", p.Health, tt.wantHealth)
+			}
+			if len(p.ActiveEffects) != tt.wantActive {
+				t.Errorf("got %d active effects, want %d", len(p.ActiveEffects), tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestRepeatedBurnFromOneSourceDoesNotStack(t *testing.T) {
+	p := NewPlayer("p", 0, 0, 1)
+	burner := NewPlayer("burner", 0, 0, 1)
+	aura := Aura{Type: "burn", Strength: 3, TickRate: 1}
+
+	for second := 0; second < 10; second++ {
+		burner.ApplyAuraEffect(&aura, p)
+		p.UpdateActiveEffects(1)
+	}
+
+	if len(p.ActiveEffects) != 1 {
+		t.Fatalf("got %d burns, want 1", len(p.ActiveEffects))
+	}
+	if p.Health != 70 {
+		t.Fatalf("health = %d after 10s of a 3 dmg/s burn, want 70", p.Health)
+	}
+}
+
+func TestApplySlow(t *testing.T) {
+	type slow struct {
+		strength float64
+		duration float64
+	}
+
+	tests := []struct {
+		name         string
+		slows        []slow
+		wantStrength float64
+		wantDuration float64
+	}{
+		{"first slow applies", []slow{{0.25, 1}}, 0.25, 1},
+		{"stronger slow replaces", []slow{{0.25, 3}, {0.4, 1}}, 0.4, 1},
+		{"weaker slow is ignored", []slow{{0.4, 1}, {0.25, 3}}, 0.4, 1},
+		{"equal slow extends", []slow{{0.3, 1}, {0.3, 2}}, 0.3, 2},
+		{"equal slow never shortens", []slow{{0.3, 2}, {0.3, 1}}, 0.3, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer("p", 0, 0, 1)
+			for _, s := range tt.slows {
+				p.ApplySlow(s.strength, s.duration)
+			}
+
+			if p.SlowEffect != tt.wantStrength {
+				t.Errorf("slow = %v, want %v", p.SlowEffect, tt.wantStrength)
+			}
+			if p.SlowDuration != tt.wantDuration {
+				t.Errorf("duration = %v, want %v", p.SlowDuration, tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestSlowAuraStrengthsArePercentages(t *testing.T) {
+	for _, card := range allCards {
+		for _, effect := range card.Effects {
+			if effect.AuraType == "slow" && effect.AuraStrength < 1 {
+				t.Errorf("%s slow aura strength %v is a fraction, want a percentage", card.Name, effect.AuraStrength)
+			}
+		}
+	}
+}
+
+func TestPushPlayer(t *testing.T) {
+	tests := []struct {
+		name    string
+		x, y    float64
+		impulse float64
+		wantVX  float64
+		wantVY  float64
+	}{
+		{"knockback pushes away", 100, 0, 5, 5, 0},
+		{"pull draws in", 100, 0, -5, -5, 0},
+		{"knockback along diagonal", -30, 40, 10, -6, 8},
+		{"overlapping players are left alone", 0, 0, 5, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewPlayer("a", 0, 0, 1)
+			target := NewPlayer("b", tt.x, tt.y, 1)
+
+			source.PushPlayer(target, tt.impulse)
+
+			if math.Abs(target.VelocityX-tt.wantVX) > 1e-9 || math.Abs(target.VelocityY-tt.wantVY) > 1e-9 {
+				t.Errorf("velocity = (%v, %v), want (%v, %v)", target.VelocityX, target.VelocityY, tt.wantVX, tt.wantVY)
+			}
+		})
+	}
+}
+
+func TestAttractPellets(t *testing.T) {
+	tests := []struct {
+		name  string
+		x, y  float64
+		dt    float64
+		wantX float64
+		wantY float64
+	}{
+		{"steps toward player", 100, 0, 0.5, 75, 0},
+		{"step scales with delta time", 0, 100, 1, 0, 50},
+		{"clamps onto player instead of overshooting", 20, 0, 1, 0, 0},
+		{"ignores pellets out of range", 300, 0, 1, 300, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer("a", 0, 0, 1)
+			aura := &Aura{Type: "magnet", Radius: 200, Strength: 50}
+			pellets := map[string]*Pellet{"x": {ID: "x", X: tt.x, Y: tt.y}}
+
+			p.AttractPellets(aura, pellets, tt.dt)
+
+			pellet := pellets["x"]
+			if math.Abs(pellet.X-tt.wantX) > 1e-9 || math.Abs(pellet.Y-tt.wantY) > 1e-9 {
+				t.Errorf("pellet at (%v, %v), want (%v, %v)", pellet.X, pellet.Y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestHealAlliesAuraOnlyHealsTeammates(t *testing.T) {
+	healer := NewPlayer("healer", 0, 0, 1)
+	healer.TeamID = "red"
+	healer.Auras = []Aura{{Type: "heal_allies", Radius: 100, Strength: 10, TickRate: 1}}
+
+	ally := NewPlayer("ally", 50, 0, 1)
+	ally.TeamID = "red"
+	enemy := NewPlayer("enemy", -50, 0, 1)
+	enemy.TeamID = "blue"
+	loner := NewPlayer("loner", 0, 50, 1)
+
+	healer.Health = 50
+	for _, p := range []*Player{ally, enemy, loner} {
+		p.Health = 50
+	}
+
+	for _, friendlyFire := range []bool{false, true} {
+		healer.UpdateAuras(1, []*Player{healer, ally, enemy, loner}, nil, friendlyFire)
+	}
+
+	if ally.Health != 70 {
+		t.Errorf("ally health = %d, want 70", ally.Health)
+	}
+	if enemy.Health != 50 {
+		t.Errorf("enemy health = %d, want 50", enemy.Health)
+	}
+	if loner.Health != 50 {
+		t.Errorf("teamless player health = %d, want 50", loner.Health)
+	}
+	if healer.Health != 50 {
+		t.Errorf("healer health = %d, want 50", healer.Health)
+	}
+}
+
+func TestRespawnResetsCardStats(t *testing.T) {
+	fresh := NewPlayer("fresh", 0, 0, 1)
+
+	p := NewPlayer("p", 0, 0, 1)
+	p.ApplyCardEffect(CardEffect{Stat: "max_health", Modifier: 2})
+	p.ApplyCardEffect(CardEffect{Stat: "absorbRange", Modifier: 1.5})
+	p.ApplyCardEffect(CardEffect{Stat: "damage", Modifier: 2})
+
+	p.Respawn(10, 10)
+
+	if p.MaxHealth != fresh.MaxHealth || p.Health != fresh.Health {
+		t.Errorf("health = %d/%d, want %d/%d", p.Health, p.MaxHealth, fresh.Health, fresh.MaxHealth)
+	}
+	if p.AbsorptionRange != fresh.AbsorptionRange {
+		t.Errorf("absorption range = %v, want %v", p.AbsorptionRange, fresh.AbsorptionRange)
+	}
+	if p.Damage != fresh.Damage {
+		t.Errorf("damage = %d, want %d", p.Damage, fresh.Damage)
+	}
+}
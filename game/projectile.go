@@ -0,0 +1,158 @@
+package game
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+type Projectile struct {
+	ID        string
+	OwnerID   string
+	X         float64
+	Y         float64
+	VelocityX float64
+	VelocityY float64
+	Size      float64
+	Damage    int
+	Lifetime  float64
+}
+
+type AutoAttack struct {
+	Damage   int
+	Interval float64
+	Range    float64
+	Speed    float64
+	LastFire float64
+}
+
+func NewProjectile(ownerID string, x, y, velocityX, velocityY float64, damage int, lifetime float64) *Projectile {
+	return &Projectile{
+		ID:        uuid.New().String(),
+		OwnerID:   ownerID,
+		X:         x,
+		Y:         y,
+		VelocityX: velocityX,
+		VelocityY: velocityY,
+		Size:      6,
+		Damage:    damage,
+		Lifetime:  lifetime,
+	}
+}
+
+func (pr *Projectile) Update(deltaTime float64) {
+	pr.X += pr.VelocityX * deltaTime
+	pr.Y += pr.VelocityY * deltaTime
+	pr.Lifetime -= deltaTime
+}
+
+func (pr *Projectile) IsHitting(target *Player) bool {
+	reach := pr.Size + float64(target.Size)
+
+	dx := target.X - pr.X
+	if dx > reach || dx < -reach {
+		return false
+	}
+	dy := target.Y - pr.Y
+	if dy > reach || dy < -reach {
+		return false
+	}
+
+	return dx*dx+dy*dy <= reach*reach
+}
+
+func (w *World) updateAutoAttacks(deltaTime float64) {
+	for _, player := range w.playerSlice {
+		for i := range player.AutoAttacks {
+			attack := &player.AutoAttacks[i]
+			attack.LastFire += deltaTime
+			if attack.LastFire < attack.Interval {
+				continue
+			}
+
+			target := w.nearestEnemy(player, attack.Range)
+			if target == nil {
+				continue
+			}
+			attack.LastFire = 0
+
+			dx := target.X - player.X
+			dy := target.Y - player.Y
+			distance := math.Sqrt(dx*dx + dy*dy)
+			if distance == 0 {
+				continue
+			}
+
+			projectile := NewProjectile(
+				player.ID,
+				player.X+dx/distance*float64(player.Size),
+				player.Y+dy/distance*float64(player.Size),
+				dx/distance*attack.Speed,
+				dy/distance*attack.Speed,
+				attack.Damage,
+				attack.Range/attack.Speed,
+			)
+			w.Projectiles[projectile.ID] = projectile
+		}
+	}
+}
+
+func (w *World) nearestEnemy(p *Player, maxRange float64) *Player {
+	var nearest *Player
+	nearestDistSq := math.MaxFloat64
+
+	for _, other := range w.playerSlice {
+		if other.ID == p.ID || p.IsAllyOf(other) || !other.IsAlive() {
+			continue
+		}
+
+		dx := other.X - p.X
+		dy := other.Y - p.Y
+		reach := maxRange + float64(other.Size)
+		distSq := dx*dx + dy*dy
+		if distSq > reach*reach || distSq >= nearestDistSq {
+			continue
+		}
+
+		nearest = other
+		nearestDistSq = distSq
+	}
+
+	return nearest
+}
+
+func (w *World) updateProjectiles(deltaTime float64) {
+	halfWorld := w.WorldSize / 2
+
+	for id, projectile := range w.Projectiles {
+		projectile.Update(deltaTime)
+
+		if projectile.Lifetime <= 0 ||
+			projectile.X < -halfWorld || projectile.X > halfWorld ||
+			projectile.Y < -halfWorld || projectile.Y > halfWorld ||
+			w.Map.Blocks(projectile.X, projectile.Y, projectile.Size) {
+			delete(w.Projectiles, id)
+			continue
+		}
+
+		owner := w.Players[projectile.OwnerID]
+
+		for _, target := range w.playerSlice {
+			if target.ID == projectile.OwnerID || !target.IsAlive() {
+				continue
+			}
+			if owner != nil && !w.canHurt(owner, target) {
+				continue
+			}
+			if !projectile.IsHitting(target) {
+				continue
+			}
+
+			if target.TakeDamage(projectile.Damage) {
+				w.handlePlayerDeath(target, owner)
+			}
+			delete(w.Projectiles, id)
+			break
+		}
+	}
+}
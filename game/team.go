@@ -0,0 +1,71 @@
+package game
+
+import "sort"
+
+type TeamScore struct {
+	ID      string
+	Score   int
+	Players int
+}
+
+func (w *World) EnableTeams(teams []string) {
+	w.Mu.Lock()
+	defer w.Mu.Unlock()
+
+	w.Teams = teams
+	for _, p := range w.Players {
+		p.TeamID = w.smallestTeam()
+	}
+}
+
+func (w *World) smallestTeam() string {
+	if len(w.Teams) == 0 {
+		return ""
+	}
+
+	counts := make(map[string]int, len(w.Teams))
+	for _, p := range w.Players {
+		counts[p.TeamID]++
+	}
+
+	smallest := w.Teams[0]
+	for _, team := range w.Teams[1:] {
+		if counts[team] < counts[smallest] {
+			smallest = team
+		}
+	}
+	return smallest
+}
+
+func (w *World) TeamScores() []TeamScore {
+	if len(w.Teams) == 0 {
+		return nil
+	}
+
+	scores := make(map[string]*TeamScore, len(w.Teams))
+	for _, team := range w.Teams {
+		scores[team] = &TeamScore{ID: team}
+	}
+
+	for _, p := range w.Players {
+		if score, ok := scores[p.TeamID]; ok {
+			score.Score += p.Score
+			score.Players++
+		}
+	}
+
+	result := make([]TeamScore, 0, len(scores))
+	for _, team := range w.Teams {
+		result = append(result, *scores[team])
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	return result
+}
+
+func (w *World) canHurt(attacker, target *Player) bool {
+	return w.FriendlyFire || !attacker.IsAllyOf(target)
+}
@@ -1,99 +1,291 @@
 package game
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+type DeathDrops struct {
+	KillerShare   float64 `json:"killer_share"`
+	DropShare     float64 `json:"drop_share"`
+	PelletValue   int     `json:"pellet_value"`
+	MaxPellets    int     `json:"max_pellets"`
+	ScatterRadius float64 `json:"scatter_radius"`
+	Lifetime      float64 `json:"lifetime"`
+}
+
+func DefaultDeathDrops() DeathDrops {
+	return DeathDrops{
+		KillerShare:   0.3,
+		DropShare:     0.6,
+		PelletValue:   5,
+		MaxPellets:    40,
+		ScatterRadius: 120,
+		Lifetime:      30.0,
+	}
+}
+
+// UnmarshalJSON starts from the defaults so a map only has to list the
+// settings it changes.
+func (d *DeathDrops) UnmarshalJSON(data []byte) error {
+	type plain DeathDrops
+	*d = DefaultDeathDrops()
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+func (d DeathDrops) Validate() error {
+	if d.KillerShare < 0 || d.DropShare < 0 {
+		return errors.New("shares must not be negative")
+	}
+	if d.KillerShare+d.DropShare > 1 {
+		return fmt.Errorf("killer and drop shares add up to %v, must be at most 1", d.KillerShare+d.DropShare)
+	}
+	if d.PelletValue <= 0 || d.MaxPellets <= 0 {
+		return errors.New("pellet value and max pellets must be positive")
+	}
+	if d.ScatterRadius < 0 || d.Lifetime <= 0 {
+		return errors.New("scatter radius must not be negative and lifetime must be positive")
+	}
+	return nil
+}
+
+type Command func(w *World)
+
 type World struct {
-	Players   map[string]*Player
-	Pellets   map[string]*Pellet
-	WorldSize float64
-	Mu        sync.RWMutex
+	Players     map[string]*Player
+	Pellets     map[string]*Pellet
+	Projectiles map[string]*Projectile
+	Hazards     []*HazardZone
+	WorldSize   float64
+	Map         *Map
+	DeathDrops  DeathDrops
 
-	playerSlice []*Player
+	RespawnEnabled bool
+	SafeZone       *SafeZone
+
+	Teams        []string
+	FriendlyFire bool
+
+	Bases []*TeamBase
+	Orbs  []*FlagOrb
+
+	Mu sync.RWMutex
+
+	commands  chan Command
+	orbEvents []OrbEvent
+
+	playerSlice     []*Player
+	decayingPellets map[string]*Pellet
+
+	hazardSpawnTimer  float64
+	randomHazardCount int
+	eliminationCount  int
 }
 
-func NewWorld(worldSize float64) *World {
+func NewWorld(gameMap *Map) *World {
+	worldSize := gameMap.Size
+
+	deathDrops := DefaultDeathDrops()
+	if gameMap.DeathDrops != nil {
+		deathDrops = *gameMap.DeathDrops
+	}
+
 	world := &World{
-		Players:     make(map[string]*Player),
-		Pellets:     make(map[string]*Pellet),
-		WorldSize:   worldSize,
-		playerSlice: make([]*Player, 0, 100),
+		Players:        make(map[string]*Player),
+		Pellets:        make(map[string]*Pellet),
+		Projectiles:    make(map[string]*Projectile),
+		WorldSize:      worldSize,
+		Map:            gameMap,
+		DeathDrops:     deathDrops,
+		RespawnEnabled: true,
+		commands:       make(chan Command, 1024),
+		playerSlice:    make([]*Player, 0, 100),
+
+		decayingPellets: make(map[string]*Pellet),
 	}
 
-	pelletCount := int(worldSize / 2)
+	world.populate()
+
+	return world
+}
+
+func (w *World) populate() {
+	for i := range w.Map.Hazards {
+		zone := w.Map.Hazards[i]
+		if zone.ID == "" {
+			zone.ID = uuid.New().String()
+		}
+		if zone.Strength == 0 {
+			zone.Strength = hazardStrengths[zone.Type]
+		}
+		zone.Lifetime = 0
+		w.Hazards = append(w.Hazards, &zone)
+	}
+
+	pelletCount := int(w.WorldSize / 2)
 	for i := 0; i < pelletCount; i++ {
-		world.SpawnPellet()
+		w.SpawnPellet()
 	}
+}
 
-	return world
+func (w *World) resetLocked() {
+	w.Pellets = make(map[string]*Pellet)
+	w.Projectiles = make(map[string]*Projectile)
+	w.Hazards = w.Hazards[:0]
+	w.decayingPellets = make(map[string]*Pellet)
+	w.hazardSpawnTimer = 0
+	w.randomHazardCount = 0
+	w.SafeZone = nil
+	w.eliminationCount = 0
+
+	w.populate()
+
+	for _, p := range w.Players {
+		x, y := w.spawnPoint(40, p.TeamID)
+		p.Respawn(x, y)
+	}
+}
+
+func (w *World) Submit(cmd Command) {
+	w.commands <- cmd
+}
+
+func (w *World) runCommands() {
+	for pending := len(w.commands); pending > 0; pending-- {
+		cmd := <-w.commands
+		cmd(w)
+	}
 }
 
 func (w *World) AddPlayer(id string) {
-	w.Mu.Lock()
-	defer w.Mu.Unlock()
+	w.AddPlayerWithSeed(id, rand.Int63())
+}
+
+func (w *World) AddPlayerWithSeed(id string, cardSeed int64) {
+	w.Submit(func(w *World) {
+		w.addPlayer(id, cardSeed)
+	})
+}
 
-	x := rand.Float64()*w.WorldSize - w.WorldSize/2
-	y := rand.Float64()*w.WorldSize - w.WorldSize/2
+func (w *World) addPlayer(id string, cardSeed int64) {
+	if _, ok := w.Players[id]; ok {
+		return
+	}
 
-	w.Players[id] = NewPlayer(id, x, y)
+	teamID := w.smallestTeam()
+	x, y := w.spawnPoint(40, teamID)
+	player := NewPlayer(id, x, y, cardSeed)
+	player.TeamID = teamID
+	if !w.RespawnEnabled {
+		player.Eliminate(0)
+	}
+	w.Players[id] = player
 }
 
 func (w *World) RemovePlayer(id string) {
-	w.Mu.Lock()
-	defer w.Mu.Unlock()
-
-	delete(w.Players, id)
+	w.Submit(func(w *World) {
+		if player, ok := w.Players[id]; ok {
+			w.dropOrbs(player)
+		}
+		delete(w.Players, id)
+	})
 }
 
 func (w *World) SetPlayerInput(id string, input PlayerInput) {
-	w.Mu.RLock()
-	player, ok := w.Players[id]
-	w.Mu.RUnlock()
+	w.Submit(func(w *World) {
+		if player, ok := w.Players[id]; ok {
+			player.SetInput(input)
+		}
+	})
+}
 
-	if ok {
-		player.SetInput(input)
-	}
+func (w *World) SetPlayerLatency(id string, latency int) {
+	w.Submit(func(w *World) {
+		if player, ok := w.Players[id]; ok {
+			player.Latency = latency
+		}
+	})
+}
+
+func (w *World) RequestAbility(id string) {
+	w.Submit(func(w *World) {
+		if player, ok := w.Players[id]; ok {
+			player.AbilityRequested = true
+		}
+	})
 }
 
 func (w *World) Update(deltaTime float64) {
 	w.Mu.Lock()
 	defer w.Mu.Unlock()
 
+	w.runCommands()
+
 	w.playerSlice = w.playerSlice[:0]
 	for _, p := range w.Players {
 		if p.IsAlive() {
 			p.Update(deltaTime)
+			if !p.IsAlive() {
+				w.handlePlayerDeath(p, nil)
+				continue
+			}
+			p.ResolveWallCollisions(w.Map)
 			w.clampPlayer(p)
 			w.playerSlice = append(w.playerSlice, p)
 		}
 	}
 
+	w.updateHazards(deltaTime)
+	w.updateSafeZone(deltaTime)
+
 	for _, player := range w.playerSlice {
-		if len(player.Auras) > 0 {
-			player.UpdateAuras(deltaTime, w.playerSlice)
+		if player.AbilityRequested {
+			w.useAbility(player)
 		}
 	}
 
+	for _, player := range w.playerSlice {
+		if len(player.Auras) > 0 && player.IsAlive() {
+			for _, victim := range player.UpdateAuras(deltaTime, w.playerSlice, w.Pellets, w.FriendlyFire) {
+				w.handlePlayerDeath(victim, player)
+			}
+		}
+	}
+
+	w.updateDecayingPellets(deltaTime)
+	w.updateAutoAttacks(deltaTime)
+	w.updateProjectiles(deltaTime)
+
 	w.checkPvPCollisions()
 	w.checkPelletCollisions()
+
+	for _, player := range w.playerSlice {
+		player.CheckAchievements()
+	}
 }
 
 func (w *World) checkPelletCollisions() {
 	for _, player := range w.playerSlice {
+		if !player.IsAlive() {
+			continue
+		}
+
 		for pelletID, pellet := range w.Pellets {
 			if player.CanEatPellet(pellet) {
-				player.Score += pellet.Value
-				if player.Health < player.MaxHealth {
-					player.Health += pellet.Value
-					if player.Health > player.MaxHealth {
-						player.Health = player.MaxHealth
-					}
-				}
+				player.EatPellet(pellet)
 				delete(w.Pellets, pelletID)
-				w.SpawnPellet()
+				if pellet.Decays() {
+					delete(w.decayingPellets, pelletID)
+				} else {
+					w.SpawnPellet()
+				}
 				break
 			}
 		}
@@ -104,13 +296,21 @@ func (w *World) checkPvPCollisions() {
 	playerCount := len(w.playerSlice)
 	for i := 0; i < playerCount; i++ {
 		p1 := w.playerSlice[i]
-		if p1.CollisionCooldown > 0 {
+		if p1.CollisionCooldown > 0 || !p1.IsAlive() {
 			continue
 		}
 
 		for j := i + 1; j < playerCount; j++ {
+			if !p1.IsAlive() {
+				break
+			}
+
 			p2 := w.playerSlice[j]
-			if p2.CollisionCooldown > 0 {
+			if p2.CollisionCooldown > 0 || !p2.IsAlive() {
+				continue
+			}
+
+			if !w.canHurt(p1, p2) {
 				continue
 			}
 
@@ -133,15 +333,36 @@ func (w *World) checkPvPCollisions() {
 }
 
 func (w *World) handlePlayerDeath(dead *Player, killer *Player) {
-	killer.Score += dead.Score
-	killer.Speed += killer.Speed / 10
-	killer.Damage += killer.Damage / 10
-	killer.MaxHealth += killer.MaxHealth / 10
-	killer.Health = killer.MaxHealth
-	killer.Size += killer.Size / 10
-
-	x := rand.Float64()*w.WorldSize - w.WorldSize/2
-	y := rand.Float64()*w.WorldSize - w.WorldSize/2
+	if dead.Eliminated {
+		return
+	}
+
+	killerBonus := int(float64(dead.Score) * w.DeathDrops.KillerShare)
+	dropValue := int(float64(dead.Score) * w.DeathDrops.DropShare)
+
+	if killer != nil {
+		killer.Score += killerBonus
+		killer.Speed += killer.Speed / 10
+		killer.Damage += killer.Damage / 10
+		killer.MaxHealth += killer.MaxHealth / 10
+		killer.Health = killer.MaxHealth
+		killer.Size += killer.Size / 10
+		killer.Rerolls++
+		killer.Kills++
+	} else {
+		dropValue += killerBonus
+	}
+
+	w.DropRemains(dead.X, dead.Y, float64(dead.Size), dropValue)
+	w.dropOrbs(dead)
+
+	if !w.RespawnEnabled {
+		w.eliminationCount++
+		dead.Eliminate(w.eliminationCount)
+		return
+	}
+
+	x, y := w.spawnPoint(40, dead.TeamID)
 	dead.Respawn(x, y)
 }
 
@@ -162,14 +383,91 @@ func (w *World) clampPlayer(p *Player) {
 	}
 }
 
+func (w *World) spawnPoint(radius float64, teamID string) (float64, float64) {
+	halfWorld := w.WorldSize/2 - radius
+
+	var x, y float64
+	for attempt := 0; attempt < 20; attempt++ {
+		x, y = w.Map.RandomSpawnPoint(teamID)
+		x = math.Max(-halfWorld, math.Min(halfWorld, x))
+		y = math.Max(-halfWorld, math.Min(halfWorld, y))
+		if !w.Map.Blocks(x, y, radius) {
+			break
+		}
+	}
+	return x, y
+}
+
 func (w *World) SpawnPellet() {
-	x := rand.Float64()*w.WorldSize - w.WorldSize/2
-	y := rand.Float64()*w.WorldSize - w.WorldSize/2
+	var x, y float64
+	for attempt := 0; attempt < 10; attempt++ {
+		x, y = w.Map.RandomPelletPoint()
+		if !w.Map.Blocks(x, y, 4) {
+			break
+		}
+	}
 
-	pellet := NewPellet(x, y)
+	var pellet *Pellet
+	switch pelletType := RandomPelletType(rand.Float64()); pelletType {
+	case "normal":
+		pellet = NewPellet(x, y)
+	case "golden":
+		pellet = NewGoldenPellet(x, y)
+	default:
+		pellet = NewPowerUpPellet(x, y, pelletType)
+	}
 	w.Pellets[pellet.ID] = pellet
 }
 
+func (w *World) DropRemains(x, y, radius float64, value int) {
+	if value <= 0 {
+		return
+	}
+
+	drops := w.DeathDrops
+	count := 1
+	if drops.PelletValue > 0 {
+		count = (value + drops.PelletValue - 1) / drops.PelletValue
+	}
+	if drops.MaxPellets > 0 && count > drops.MaxPellets {
+		count = drops.MaxPellets
+	}
+
+	halfWorld := w.WorldSize / 2
+	remaining := value
+
+	for i := 0; i < count; i++ {
+		pelletValue := remaining / (count - i)
+		remaining -= pelletValue
+
+		px, py := x, y
+		for attempt := 0; attempt < 10; attempt++ {
+			angle := rand.Float64() * 2 * math.Pi
+			distance := rand.Float64() * (radius + drops.ScatterRadius)
+			px = math.Max(-halfWorld, math.Min(halfWorld, x+math.Cos(angle)*distance))
+			py = math.Max(-halfWorld, math.Min(halfWorld, y+math.Sin(angle)*distance))
+			if !w.Map.Blocks(px, py, 4) {
+				break
+			}
+			px, py = x, y
+		}
+
+		pellet := NewRemainsPellet(px, py, pelletValue, drops.Lifetime)
+		w.Pellets[pellet.ID] = pellet
+		w.decayingPellets[pellet.ID] = pellet
+	}
+}
+
+func (w *World) updateDecayingPellets(deltaTime float64) {
+	for id, pellet := range w.decayingPellets {
+		pellet.Lifetime -= deltaTime
+		if pellet.Lifetime <= 0 {
+			delete(w.decayingPellets, id)
+			delete(w.Pellets, id)
+		}
+	}
+}
+
 func (w *World) Run(tickRate time.Duration) {
 	ticker := time.NewTicker(tickRate)
 	defer ticker.Stop()
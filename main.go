@@ -1,9 +1,15 @@
 package main
 
 import (
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
 	"log"
+	"net"
 	"net/http"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DCCXXV/orbwars.io/game"
@@ -17,12 +23,20 @@ import (
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
 }
 
 func main() {
+	mapPath := flag.String("map", "./maps/arena.json", "map file to load, empty for an open world")
+	modeName := flag.String("mode", "ffa", "game mode: ffa, br, koth or cto")
+	teams := flag.Bool("teams", false, "split players into red and blue teams")
+	friendlyFire := flag.Bool("friendly-fire", false, "allow teammates to damage each other in team modes")
+	origins := flag.String("origins", "", "comma separated list of allowed websocket origins, empty allows any")
+	chatFilter := flag.String("chat-filter", "", "comma separated list of words to mask in chat")
+	adminToken := flag.String("admin-token", "", "bearer token for admin endpoints, empty disables them")
+	maxConnsPerIP := flag.Int("max-conns-per-ip", 4, "maximum simultaneous connections from one IP, 0 for unlimited")
+	maxSpectatorsPerIP := flag.Int("max-spectators-per-ip", 2, "maximum simultaneous spectators from one IP, 0 for unlimited")
+	flag.Parse()
+
 	runtime.GOMAXPROCS(2)
 
 	if err := game.LoadCards("./cards.json"); err != nil {
@@ -30,15 +44,60 @@ func main() {
 	}
 	log.Println("Cards loaded succesfully")
 
-	worldSize := 8000.0
-	world := game.NewWorld(worldSize)
+	gameMap := game.NewEmptyMap(8000.0)
+	if *mapPath != "" {
+		loaded, err := game.LoadMap(*mapPath)
+		if err != nil {
+			log.Fatal("Error when loading map:", err)
+		}
+		gameMap = loaded
+	}
+	log.Printf("Map '%s' loaded with %d walls", gameMap.Name, len(gameMap.Walls))
+
+	world := game.NewWorld(gameMap)
 	log.Println("World created with", len(world.Pellets), "pellets")
 
 	hub := realtime.NewHub(world)
+	hub.Guard.MaxConnsPerIP = *maxConnsPerIP
+	hub.Guard.MaxSpectatorsPerIP = *maxSpectatorsPerIP
+	if *origins != "" {
+		hub.Guard.AllowedOrigins = strings.Split(*origins, ",")
+	}
+	upgrader.CheckOrigin = hub.Guard.CheckOrigin
+	if *chatFilter != "" {
+		hub.Chat.Filter = realtime.NewWordFilter(strings.Split(*chatFilter, ","))
+	}
+
+	if *modeName == "cto" {
+		*teams = true
+	}
+
+	if *teams {
+		world.EnableTeams([]string{"red", "blue"})
+		world.FriendlyFire = *friendlyFire
+		log.Println("Teams enabled")
+	}
+
+	var sim simulation = world
+	var mode game.Mode
+	switch *modeName {
+	case "br":
+		mode = game.NewMatch(world, game.DefaultMatchConfig(world.WorldSize))
+	case "koth":
+		mode = game.NewKingOfTheHill(world, game.DefaultKingOfTheHillConfig())
+	case "cto":
+		mode = game.NewCaptureTheOrb(world, game.DefaultCaptureTheOrbConfig())
+	}
+	if mode != nil {
+		hub.SetMode(mode)
+		sim = mode
+		log.Printf("Game mode %s enabled", *modeName)
+	}
+
 	go hub.Run()
 	log.Println("Websockets hub started")
 
-	go startGameLoop(world)
+	go startGameLoop(sim, hub)
 	log.Println("Gameloop started")
 
 	go startBroadcasting(hub)
@@ -52,6 +111,17 @@ func main() {
 		handleWebSocket(hub, w, req)
 	})
 
+	r.Get("/metrics", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hub.Guard.Metrics())
+	})
+
+	if *adminToken != "" {
+		r.Post("/admin/mute", func(w http.ResponseWriter, req *http.Request) {
+			handleMute(hub, *adminToken, w, req)
+		})
+	}
+
 	r.Handle("/*", http.FileServer(http.Dir("./web")))
 
 	log.Println("Server running on http://localhost:6767")
@@ -61,27 +131,71 @@ func main() {
 }
 
 func handleWebSocket(hub *realtime.Hub, w http.ResponseWriter, r *http.Request) {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	spectate := r.URL.Query().Get("spectate") == "1"
+
+	admit := hub.Guard.Admit
+	if spectate {
+		admit = hub.Guard.AdmitSpectator
+	}
+	if ok, reason := admit(ip); !ok {
+		log.Printf("Rejected connection from %s: %s", ip, reason)
+		http.Error(w, reason, http.StatusTooManyRequests)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
+		if spectate {
+			hub.Guard.ReleaseSpectator(ip)
+		} else {
+			hub.Guard.Release(ip)
+		}
 		log.Println("Error when making upgrade", err)
 		return
 	}
 
 	clientID := generateClientID()
-	client := &realtime.Client{
-		ID:   clientID,
-		Conn: conn,
-		Send: make(chan []byte, 256),
-		Hub:  hub,
-	}
-
-	hub.Register <- client
+	client := realtime.NewClient(clientID, ip, conn, hub)
+	client.Spectator = spectate
 
 	go client.WritePump()
 	go client.ReadPump()
 }
 
-func startGameLoop(world *game.World) {
+// handleMute mutes a player by ID. If the player reconnects within a minute,
+// the first new player from the same IP inherits the mute.
+func handleMute(hub *realtime.Hub, token string, w http.ResponseWriter, r *http.Request) {
+	auth := []byte(r.Header.Get("Authorization"))
+	if subtle.ConstantTimeCompare(auth, []byte("Bearer "+token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	playerID := r.URL.Query().Get("player_id")
+	if playerID == "" {
+		http.Error(w, "player_id is required", http.StatusBadRequest)
+		return
+	}
+
+	minutes, err := strconv.Atoi(r.URL.Query().Get("minutes"))
+	if err != nil || minutes <= 0 {
+		minutes = 10
+	}
+
+	hub.MutePlayer(playerID, time.Duration(minutes)*time.Minute)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type simulation interface {
+	Update(deltaTime float64)
+}
+
+func startGameLoop(sim simulation, hub *realtime.Hub) {
 	ticker := time.NewTicker(time.Second / 60)
 	defer ticker.Stop()
 
@@ -92,7 +206,8 @@ func startGameLoop(world *game.World) {
 		deltaTime := now.Sub(lastTime).Seconds()
 		lastTime = now
 
-		world.Update(deltaTime)
+		sim.Update(deltaTime)
+		hub.PublishSnapshot()
 	}
 }
 
@@ -0,0 +1,294 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxChatLength = 200
+	chatHistory   = 50
+
+	// muteReconnectWindow is how long a muted player's IP keeps the mute
+	// after they disconnect, so reconnecting with a new ID doesn't lift it.
+	muteReconnectWindow = time.Minute
+)
+
+const (
+	ChatScopeAll  = "all"
+	ChatScopeTeam = "team"
+)
+
+type ChatFilter interface {
+	Filter(text string) (string, bool)
+}
+
+type WordFilter struct {
+	patterns []*regexp.Regexp
+}
+
+type ChatMessage struct {
+	Text  string `json:"text"`
+	Scope string `json:"scope,omitempty"`
+}
+
+type ChatBlockMessage struct {
+	PlayerID string `json:"player_id"`
+	Blocked  bool   `json:"blocked"`
+}
+
+type ChatData struct {
+	PlayerID string `json:"player_id"`
+	TeamID   string `json:"team_id,omitempty"`
+	Text     string `json:"text"`
+	TeamOnly bool   `json:"team_only,omitempty"`
+	Time     int64  `json:"time"`
+}
+
+type ChatHistoryData struct {
+	Messages []ChatData `json:"messages"`
+}
+
+type Chat struct {
+	Filter ChatFilter
+
+	history  []ChatData
+	next     int
+	muted    map[string]time.Time
+	mutedIPs map[string]ipMute
+}
+
+type ipMute struct {
+	until   time.Time
+	claimBy time.Time
+}
+
+func NewChat() *Chat {
+	return &Chat{
+		Filter:   WordFilter{},
+		history:  make([]ChatData, 0, chatHistory),
+		muted:    make(map[string]time.Time),
+		mutedIPs: make(map[string]ipMute),
+	}
+}
+
+func NewWordFilter(words []string) WordFilter {
+	var f WordFilter
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		f.patterns = append(f.patterns, regexp.MustCompile("(?i)"+regexp.QuoteMeta(word)))
+	}
+	return f
+}
+
+func (f WordFilter) Filter(text string) (string, bool) {
+	for _, pattern := range f.patterns {
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(match))
+		})
+	}
+	return text, true
+}
+
+func (m *ChatMessage) Validate() error {
+	m.Text = strings.TrimSpace(m.Text)
+	if m.Text == "" {
+		return errors.New("chat message is empty")
+	}
+	if utf8.RuneCountInString(m.Text) > maxChatLength {
+		return fmt.Errorf("chat message is longer than %d characters", maxChatLength)
+	}
+
+	switch m.Scope {
+	case "":
+		m.Scope = ChatScopeAll
+	case ChatScopeAll, ChatScopeTeam:
+	default:
+		return errors.New("scope must be all or team")
+	}
+	return nil
+}
+
+func (m *ChatBlockMessage) Validate() error {
+	if m.PlayerID == "" {
+		return errors.New("player_id is required")
+	}
+	return nil
+}
+
+func (c *Chat) record(msg ChatData) {
+	if len(c.history) < chatHistory {
+		c.history = append(c.history, msg)
+		return
+	}
+	c.history[c.next] = msg
+	c.next = (c.next + 1) % chatHistory
+}
+
+func (c *Chat) recent() []ChatData {
+	messages := make([]ChatData, 0, len(c.history))
+	messages = append(messages, c.history[c.next:]...)
+	messages = append(messages, c.history[:c.next]...)
+	return messages
+}
+
+func (c *Chat) isMuted(playerID string) bool {
+	until, ok := c.muted[playerID]
+	if !ok {
+		return false
+	}
+	if time.Now().Before(until) {
+		return true
+	}
+	delete(c.muted, playerID)
+	return false
+}
+
+// carryMute holds a disconnecting muted player's mute on their IP for a short
+// while. Other players behind the same IP who are already connected are not
+// affected.
+func (c *Chat) carryMute(client *Client) {
+	if !c.isMuted(client.ID) {
+		return
+	}
+
+	now := time.Now()
+	for ip, m := range c.mutedIPs {
+		if now.After(m.claimBy) {
+			delete(c.mutedIPs, ip)
+		}
+	}
+
+	c.mutedIPs[client.IP] = ipMute{
+		until:   c.muted[client.ID],
+		claimBy: now.Add(muteReconnectWindow),
+	}
+	delete(c.muted, client.ID)
+}
+
+// inheritMute hands a carried mute to the first player joining from the
+// muted IP within the reconnect window.
+func (c *Chat) inheritMute(client *Client) {
+	m, ok := c.mutedIPs[client.IP]
+	if !ok {
+		return
+	}
+	delete(c.mutedIPs, client.IP)
+
+	if time.Now().After(m.claimBy) {
+		return
+	}
+	c.muted[client.ID] = m.until
+	log.Printf("Player %s (%s) reconnected while muted", client.ID, client.IP)
+}
+
+func (h *Hub) MutePlayer(playerID string, duration time.Duration) {
+	h.Submit(func(h *Hub) {
+		h.mute(playerID, duration)
+	})
+}
+
+func (h *Hub) mute(playerID string, duration time.Duration) {
+	h.Chat.muted[playerID] = time.Now().Add(duration)
+	log.Printf("Player %s muted for %s", playerID, duration)
+}
+
+func (h *Hub) sendChat(sender *Client, text, scope string) {
+	if h.Clients[sender.ID] != sender {
+		return
+	}
+
+	if h.Chat.isMuted(sender.ID) {
+		h.deliver(sender, errorMessage(MsgChat, errors.New("you are muted")))
+		return
+	}
+
+	text, ok := h.Chat.Filter.Filter(text)
+	if !ok {
+		h.deliver(sender, errorMessage(MsgChat, errors.New("message was blocked by the chat filter")))
+		return
+	}
+
+	msg := ChatData{
+		PlayerID: sender.ID,
+		Text:     text,
+		Time:     time.Now().UnixMilli(),
+	}
+
+	if len(h.World.Teams) > 0 {
+		if snapshot := h.Snapshot(); snapshot != nil {
+			if player := findPlayer(snapshot.State.Players, sender.ID); player != nil {
+				msg.TeamID = player.TeamID
+			}
+		}
+	}
+
+	if scope == ChatScopeTeam {
+		if msg.TeamID == "" {
+			h.deliver(sender, errorMessage(MsgChat, errors.New("team chat needs a team")))
+			return
+		}
+		msg.TeamOnly = true
+	}
+
+	if !msg.TeamOnly {
+		h.Chat.record(msg)
+	}
+
+	data, err := json.Marshal(ServerMessage{
+		Type: MsgChat,
+		Data: msg,
+	})
+	if err != nil {
+		log.Println("Error serializing chat message:", err)
+		return
+	}
+
+	for _, client := range h.Clients {
+		if client.blocked[sender.ID] {
+			continue
+		}
+		if msg.TeamOnly && client != sender && !h.sameTeam(client, msg.TeamID) {
+			continue
+		}
+		h.deliver(client, data)
+	}
+}
+
+func (h *Hub) sameTeam(client *Client, teamID string) bool {
+	if client.Spectator {
+		return false
+	}
+
+	snapshot := h.Snapshot()
+	if snapshot == nil {
+		return false
+	}
+
+	player := findPlayer(snapshot.State.Players, client.ID)
+	return player != nil && player.TeamID == teamID
+}
+
+func (h *Hub) sendChatHistory(client *Client) {
+	data, err := json.Marshal(ServerMessage{
+		Type: MsgChatHistory,
+		Data: ChatHistoryData{
+			Messages: h.Chat.recent(),
+		},
+	})
+	if err != nil {
+		log.Println("Error serializing chat history:", err)
+		return
+	}
+
+	client.Send <- data
+}
@@ -0,0 +1,166 @@
+package realtime
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWordFilter(t *testing.T) {
+	filter := NewWordFilter([]string{"heck", " darn ", ""})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"what the heck", "what the ****"},
+		{"HECK and Darn", "**** and ****"},
+		{"nothing to see", "nothing to see"},
+		{"a.b+c", "a.b+c"},
+	}
+
+	for _, tt := range tests {
+		got, ok := filter.Filter(tt.text)
+		if !ok || got != tt.want {
+			t.Errorf("Filter(%q) = %q, %v, want %q", tt.text, got, ok, tt.want)
+		}
+	}
+}
+
+func TestMuteSurvivesReconnect(t *testing.T) {
+	hub, _ := newTestHub()
+
+	muted := NewClient("muted", "1.1.1.1", nil, hub)
+	neighbour := NewClient("neighbour", "1.1.1.1", nil, hub)
+	hub.Clients[muted.ID] = muted
+	hub.Clients[neighbour.ID] = neighbour
+
+	hub.mute(muted.ID, time.Hour)
+	if !hub.Chat.isMuted(muted.ID) {
+		t.Fatal("muted player can still chat")
+	}
+	if hub.Chat.isMuted(neighbour.ID) {
+		t.Fatal("mute silenced another player behind the same IP")
+	}
+
+	hub.disconnect(muted, "test")
+
+	reconnected := NewClient("reconnected", "1.1.1.1", nil, hub)
+	hub.Chat.inheritMute(reconnected)
+	if !hub.Chat.isMuted(reconnected.ID) {
+		t.Fatal("mute was lifted by reconnecting with a new ID")
+	}
+
+	later := NewClient("later", "1.1.1.1", nil, hub)
+	hub.Chat.inheritMute(later)
+	if hub.Chat.isMuted(later.ID) || hub.Chat.isMuted(neighbour.ID) {
+		t.Fatal("carried mute reached more than the reconnecting player")
+	}
+}
+
+func TestCarriedMuteExpiresAfterWindow(t *testing.T) {
+	hub, _ := newTestHub()
+
+	hub.Chat.mutedIPs["1.1.1.1"] = ipMute{
+		until:   time.Now().Add(time.Hour),
+		claimBy: time.Now().Add(-time.Second),
+	}
+
+	client := NewClient("a", "1.1.1.1", nil, hub)
+	hub.Chat.inheritMute(client)
+	if hub.Chat.isMuted(client.ID) {
+		t.Fatal("mute was inherited after the reconnect window")
+	}
+}
+
+func TestMuteExpires(t *testing.T) {
+	hub, _ := newTestHub()
+
+	hub.mute("a", -time.Second)
+
+	if hub.Chat.isMuted("a") {
+		t.Fatal("expired mute still applies")
+	}
+	if len(hub.Chat.muted) != 0 {
+		t.Fatalf("expired mutes were not cleaned up: %v", hub.Chat.muted)
+	}
+}
+
+func chatRecipients(clients ...*Client) []string {
+	var ids []string
+	for _, c := range clients {
+		for len(c.Send) > 0 {
+			if data := <-c.Send; strings.Contains(string(data), `"type":"chat"`) {
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+	return ids
+}
+
+func TestChatScope(t *testing.T) {
+	hub, world := newTestHub()
+	world.EnableTeams([]string{"red", "blue"})
+
+	clients := make(map[string]*Client)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		addTestPlayer(t, world, id)
+		clients[id] = NewClient(id, id, nil, hub)
+		hub.Clients[id] = clients[id]
+	}
+	hub.PublishSnapshot()
+
+	sender := clients["a"]
+	var ally, enemy string
+	for id, p := range world.Players {
+		if id == sender.ID {
+			continue
+		}
+		if p.TeamID == world.Players[sender.ID].TeamID {
+			ally = id
+		} else {
+			enemy = id
+		}
+	}
+
+	all := []*Client{clients["a"], clients["b"], clients["c"], clients["d"]}
+
+	hub.sendChat(sender, "hello room", ChatScopeAll)
+	if got := chatRecipients(all...); len(got) != 4 {
+		t.Fatalf("room chat reached %v, want everyone", got)
+	}
+
+	hub.sendChat(sender, "hello team", ChatScopeTeam)
+	got := chatRecipients(all...)
+	if !slices.Contains(got, sender.ID) || !slices.Contains(got, ally) || slices.Contains(got, enemy) {
+		t.Fatalf("team chat reached %v, want only %s and %s", got, sender.ID, ally)
+	}
+}
+
+func TestTeamChatWithoutTeams(t *testing.T) {
+	hub, world := newTestHub()
+	addTestPlayer(t, world, "a")
+	sender := NewClient("a", "1.1.1.1", nil, hub)
+	hub.Clients[sender.ID] = sender
+	hub.PublishSnapshot()
+
+	hub.sendChat(sender, "anyone?", ChatScopeTeam)
+
+	data := <-sender.Send
+	if !strings.Contains(string(data), `"type":"error"`) {
+		t.Fatalf("got %s, want an error", data)
+	}
+}
+
+func TestChatMessageScopeValidation(t *testing.T) {
+	msg := ChatMessage{Text: "hi"}
+	if err := msg.Validate(); err != nil || msg.Scope != ChatScopeAll {
+		t.Fatalf("empty scope: %v, %q", err, msg.Scope)
+	}
+
+	msg = ChatMessage{Text: "hi", Scope: "whisper"}
+	if err := msg.Validate(); err == nil {
+		t.Fatal("unknown scope was accepted")
+	}
+}
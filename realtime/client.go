@@ -2,19 +2,141 @@ package realtime
 
 import (
 	"encoding/json"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
 
-	"github.com/DCCXXV/orbwars.io/game"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	maxStateLag    = 120
+	writeWait      = 10 * time.Second
+	pongWait       = 15 * time.Second
+	pingPeriod     = 2 * time.Second
+	maxMessageSize = 4096
+)
+
 type Client struct {
 	ID   string
+	IP   string
 	Conn *websocket.Conn
 	Send chan []byte
 	Hub  *Hub
+
+	Protocol  int
+	Features  []string
+	Spectator bool
+
+	camera atomic.Pointer[Camera]
+
+	state chan stateFrame
+	lag   int
+
+	limits map[string]*TokenBucket
+	abuse  *TokenBucket
+
+	blocked map[string]bool
+}
+
+func NewClient(id, ip string, conn *websocket.Conn, hub *Hub) *Client {
+	return &Client{
+		ID:     id,
+		IP:     ip,
+		Conn:   conn,
+		Send:   make(chan []byte, 256),
+		Hub:    hub,
+		state:  make(chan stateFrame, 1),
+		limits: make(map[string]*TokenBucket),
+		abuse:  NewTokenBucket(abuseRate, abuseBurst),
+
+		blocked: make(map[string]bool),
+	}
+}
+
+func (c *Client) kick(reason string) {
+	guard := c.Hub.Guard
+	guard.kicks.Add(1)
+	guard.Ban(c.IP)
+
+	closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+	c.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait))
+	log.Printf("Kicked player %s (%s): %s", c.ID, c.IP, reason)
+}
+
+func (c *Client) release() {
+	if c.Spectator {
+		c.Hub.Guard.ReleaseSpectator(c.IP)
+	} else {
+		c.Hub.Guard.Release(c.IP)
+	}
+}
+
+func (c *Client) queueState(frame stateFrame) bool {
+	dropped := false
+	select {
+	case <-c.state:
+		dropped = true
+	default:
+	}
+	c.state <- frame
+
+	if dropped {
+		c.lag++
+	} else {
+		c.lag = 0
+	}
+	return dropped
+}
+
+func (c *Client) handshake() bool {
+	_, message, err := c.Conn.ReadMessage()
+	if err != nil {
+		return false
+	}
+
+	var msg struct {
+		Type string       `json:"type"`
+		Data HelloMessage `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil || msg.Type != MsgHello {
+		msg.Data.Version = 0
+	}
+
+	if msg.Data.Version < MinProtocolVersion || msg.Data.Version > ProtocolVersion {
+		log.Printf("Rejected client %s with protocol version %d", c.ID, msg.Data.Version)
+		data, err := json.Marshal(ServerMessage{
+			Type: MsgVersionMismatch,
+			Data: VersionMismatchData{
+				ClientVersion: msg.Data.Version,
+				ServerVersion: ProtocolVersion,
+				MinVersion:    MinProtocolVersion,
+			},
+		})
+		if err == nil {
+			c.Send <- data
+		}
+		return false
+	}
+
+	c.Protocol = msg.Data.Version
+	c.Features = negotiateFeatures(msg.Data.Features)
+	return true
 }
 
 func (c *Client) ReadPump() {
+	c.Conn.SetReadLimit(maxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.Conn.SetPongHandler(c.handlePong)
+
+	if !c.handshake() {
+		close(c.Send)
+		c.release()
+		return
+	}
+
+	c.Hub.Register <- c
 	defer func() {
 		c.Hub.Unregister <- c
 		c.Conn.Close()
@@ -28,37 +150,86 @@ func (c *Client) ReadPump() {
 
 		var msg ClientMessage
 		if err := json.Unmarshal(message, &msg); err != nil {
+			c.Hub.Guard.malformedMessages.Add(1)
+			if !c.strike() {
+				c.kick("too many malformed messages")
+				return
+			}
+			continue
+		}
+
+		if !c.allowMessage(msg.Type) {
+			c.Hub.Guard.rateLimited.Add(1)
+			if !c.strike() {
+				c.kick("rate limit exceeded")
+				return
+			}
 			continue
 		}
 
-		switch msg.Type {
-		case "input":
-			dataBytes, _ := json.Marshal(msg.Data)
-			var input InputMessage
-			json.Unmarshal(dataBytes, &input)
-
-			c.Hub.World.SetPlayerInput(c.ID, game.PlayerInput{
-				W: input.W,
-				A: input.A,
-				S: input.S,
-				D: input.D,
-			})
-
-		case "card_choice":
-			dataBytes, _ := json.Marshal(msg.Data)
-			var choice CardChoiceMessage
-			json.Unmarshal(dataBytes, &choice)
-			c.Hub.HandleCardChoice(c.ID, choice.CardID)
+		if err := c.Hub.HandleMessage(c, msg); err != nil {
+			c.Hub.sendError(c.ID, msg.Type, err)
+			c.Hub.Guard.malformedMessages.Add(1)
+			if !c.strike() {
+				c.kick("too many invalid messages")
+				return
+			}
 		}
 	}
 }
 
+func (c *Client) handlePong(appData string) error {
+	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+
+	sent, err := strconv.ParseInt(appData, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	rtt := time.Since(time.Unix(0, sent))
+	c.Hub.World.SetPlayerLatency(c.ID, int(rtt.Milliseconds()))
+	return nil
+}
+
+func (c *Client) write(messageType int, data []byte) error {
+	c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.Conn.WriteMessage(messageType, data)
+}
+
 func (c *Client) WritePump() {
-	defer c.Conn.Close()
+	pingTicker := time.NewTicker(pingPeriod)
+	defer func() {
+		pingTicker.Stop()
+		c.Conn.Close()
+	}()
 
-	for message := range c.Send {
-		if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
-			break
+	for {
+		select {
+		case message, ok := <-c.Send:
+			if !ok {
+				c.write(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.write(websocket.TextMessage, message); err != nil {
+				return
+			}
+
+		case frame := <-c.state:
+			data := frame.data
+			if c.Spectator {
+				if data = c.spectatorView(frame.snapshot); data == nil {
+					continue
+				}
+			}
+			if err := c.write(websocket.TextMessage, data); err != nil {
+				return
+			}
+
+		case <-pingTicker.C:
+			payload := strconv.FormatInt(time.Now().UnixNano(), 10)
+			if err := c.write(websocket.PingMessage, []byte(payload)); err != nil {
+				return
+			}
 		}
 	}
 }
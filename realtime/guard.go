@@ -0,0 +1,168 @@
+package realtime
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type GuardMetrics struct {
+	Connections          int64 `json:"connections"`
+	Spectators           int64 `json:"spectators"`
+	RejectedOrigins      int64 `json:"rejected_origins"`
+	RejectedConnCap      int64 `json:"rejected_conn_cap"`
+	RejectedSpectatorCap int64 `json:"rejected_spectator_cap"`
+	RejectedBanned       int64 `json:"rejected_banned"`
+	RateLimited          int64 `json:"rate_limited"`
+	MalformedMessages    int64 `json:"malformed_messages"`
+	Kicks                int64 `json:"kicks"`
+	Bans                 int64 `json:"bans"`
+}
+
+type Guard struct {
+	MaxConnsPerIP      int
+	MaxSpectatorsPerIP int
+	AllowedOrigins     []string
+	BanDuration        time.Duration
+
+	mu         sync.Mutex
+	conns      map[string]int
+	spectators map[string]int
+	bans       map[string]time.Time
+
+	connections          atomic.Int64
+	spectatorConns       atomic.Int64
+	rejectedOrigins      atomic.Int64
+	rejectedConnCap      atomic.Int64
+	rejectedSpectatorCap atomic.Int64
+	rejectedBanned       atomic.Int64
+	rateLimited          atomic.Int64
+	malformedMessages    atomic.Int64
+	kicks                atomic.Int64
+	bansIssued           atomic.Int64
+}
+
+func NewGuard(maxConnsPerIP int, allowedOrigins []string) *Guard {
+	return &Guard{
+		MaxConnsPerIP:      maxConnsPerIP,
+		MaxSpectatorsPerIP: 2,
+		AllowedOrigins:     allowedOrigins,
+		BanDuration:        5 * time.Minute,
+		conns:              make(map[string]int),
+		spectators:         make(map[string]int),
+		bans:               make(map[string]time.Time),
+	}
+}
+
+func (g *Guard) CheckOrigin(r *http.Request) bool {
+	if len(g.AllowedOrigins) == 0 {
+		return true
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(g.AllowedOrigins, origin) {
+		return true
+	}
+
+	g.rejectedOrigins.Add(1)
+	return false
+}
+
+func (g *Guard) Admit(ip string) (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.bannedLocked(ip) {
+		g.rejectedBanned.Add(1)
+		return false, "temporarily banned"
+	}
+
+	if g.MaxConnsPerIP > 0 && g.conns[ip] >= g.MaxConnsPerIP {
+		g.rejectedConnCap.Add(1)
+		return false, "too many connections"
+	}
+
+	g.conns[ip]++
+	g.connections.Add(1)
+	return true, ""
+}
+
+func (g *Guard) AdmitSpectator(ip string) (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.bannedLocked(ip) {
+		g.rejectedBanned.Add(1)
+		return false, "temporarily banned"
+	}
+
+	if g.MaxSpectatorsPerIP > 0 && g.spectators[ip] >= g.MaxSpectatorsPerIP {
+		g.rejectedSpectatorCap.Add(1)
+		return false, "too many spectators"
+	}
+
+	g.spectators[ip]++
+	g.spectatorConns.Add(1)
+	return true, ""
+}
+
+func (g *Guard) bannedLocked(ip string) bool {
+	until, ok := g.bans[ip]
+	if !ok {
+		return false
+	}
+	if time.Now().Before(until) {
+		return true
+	}
+	delete(g.bans, ip)
+	return false
+}
+
+func (g *Guard) Release(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	decrement(g.conns, ip)
+	g.connections.Add(-1)
+}
+
+func (g *Guard) ReleaseSpectator(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	decrement(g.spectators, ip)
+	g.spectatorConns.Add(-1)
+}
+
+func decrement(counts map[string]int, ip string) {
+	if counts[ip] <= 1 {
+		delete(counts, ip)
+	} else {
+		counts[ip]--
+	}
+}
+
+func (g *Guard) Ban(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.bans[ip] = time.Now().Add(g.BanDuration)
+	g.bansIssued.Add(1)
+}
+
+func (g *Guard) Metrics() GuardMetrics {
+	return GuardMetrics{
+		Connections:          g.connections.Load(),
+		Spectators:           g.spectatorConns.Load(),
+		RejectedOrigins:      g.rejectedOrigins.Load(),
+		RejectedConnCap:      g.rejectedConnCap.Load(),
+		RejectedSpectatorCap: g.rejectedSpectatorCap.Load(),
+		RejectedBanned:       g.rejectedBanned.Load(),
+		RateLimited:          g.rateLimited.Load(),
+		MalformedMessages:    g.malformedMessages.Load(),
+		Kicks:                g.kicks.Load(),
+		Bans:                 g.bansIssued.Load(),
+	}
+}
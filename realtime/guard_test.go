@@ -0,0 +1,33 @@
+package realtime
+
+import "testing"
+
+func TestAdmitSpectatorCapsPerIP(t *testing.T) {
+	guard := NewGuard(1, nil)
+	guard.MaxSpectatorsPerIP = 2
+
+	for i := 0; i < 2; i++ {
+		if ok, reason := guard.AdmitSpectator("1.1.1.1"); !ok {
+			t.Fatalf("spectator %d rejected: %s", i, reason)
+		}
+	}
+	if ok, _ := guard.AdmitSpectator("1.1.1.1"); ok {
+		t.Fatal("third spectator from the same IP was admitted")
+	}
+	if ok, reason := guard.AdmitSpectator("2.2.2.2"); !ok {
+		t.Fatalf("spectator from another IP rejected: %s", reason)
+	}
+	if ok, reason := guard.Admit("1.1.1.1"); !ok {
+		t.Fatalf("player rejected by spectator cap: %s", reason)
+	}
+
+	guard.ReleaseSpectator("1.1.1.1")
+	if ok, reason := guard.AdmitSpectator("1.1.1.1"); !ok {
+		t.Fatalf("released spectator slot not reused: %s", reason)
+	}
+
+	metrics := guard.Metrics()
+	if metrics.Spectators != 3 || metrics.Connections != 1 || metrics.RejectedSpectatorCap != 1 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+}
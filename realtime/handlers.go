@@ -0,0 +1,122 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/DCCXXV/orbwars.io/game"
+)
+
+type handlerFunc func(h *Hub, c *Client, data json.RawMessage) error
+
+type validator interface {
+	Validate() error
+}
+
+type emptyPayload struct{}
+
+var handlers = map[string]handlerFunc{
+	MsgInput: handle(func(h *Hub, c *Client, input InputMessage) {
+		h.World.SetPlayerInput(c.ID, game.PlayerInput{
+			W: input.W,
+			A: input.A,
+			S: input.S,
+			D: input.D,
+		})
+	}),
+
+	MsgCardChoice: handle(func(h *Hub, c *Client, choice CardChoiceMessage) {
+		h.HandleCardChoice(c.ID, choice.CardID)
+	}),
+
+	MsgCardReroll: handle(func(h *Hub, c *Client, _ emptyPayload) {
+		h.HandleCardReroll(c.ID)
+	}),
+
+	MsgCardBanish: handle(func(h *Hub, c *Client, banish CardBanishMessage) {
+		h.HandleCardBanish(c.ID, banish.CardID)
+	}),
+
+	MsgAbility: handle(func(h *Hub, c *Client, _ emptyPayload) {
+		h.World.RequestAbility(c.ID)
+	}),
+
+	MsgChat: handle(func(h *Hub, c *Client, chat ChatMessage) {
+		h.Submit(func(h *Hub) {
+			h.sendChat(c, chat.Text, chat.Scope)
+		})
+	}),
+
+	MsgChatBlock: handle(func(h *Hub, c *Client, block ChatBlockMessage) {
+		h.Submit(func(h *Hub) {
+			if block.Blocked {
+				c.blocked[block.PlayerID] = true
+			} else {
+				delete(c.blocked, block.PlayerID)
+			}
+		})
+	}),
+
+	MsgEmote: handle(func(h *Hub, c *Client, emote EmoteMessage) {
+		h.World.RequestEmote(c.ID, emote.Emote)
+	}),
+
+	MsgSpectate: handle(func(h *Hub, c *Client, spectate SpectateMessage) {
+		c.SetCamera(Camera{
+			Mode:     spectate.Mode,
+			FollowID: spectate.FollowID,
+			X:        spectate.X,
+			Y:        spectate.Y,
+		})
+	}),
+}
+
+var spectatorMessages = map[string]bool{
+	MsgSpectate: true,
+}
+
+func handle[T any](fn func(h *Hub, c *Client, payload T)) handlerFunc {
+	return func(h *Hub, c *Client, data json.RawMessage) error {
+		var payload T
+		if len(data) > 0 && string(data) != "null" {
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return fmt.Errorf("invalid payload: %w", err)
+			}
+		}
+
+		if v, ok := any(&payload).(validator); ok {
+			if err := v.Validate(); err != nil {
+				return err
+			}
+		}
+
+		fn(h, c, payload)
+		return nil
+	}
+}
+
+func (m *CardChoiceMessage) Validate() error {
+	return validateCardID(m.CardID)
+}
+
+func (m *CardBanishMessage) Validate() error {
+	return validateCardID(m.CardID)
+}
+
+func (m *EmoteMessage) Validate() error {
+	if !game.IsEmote(m.Emote) {
+		return fmt.Errorf("unknown emote %q", m.Emote)
+	}
+	return nil
+}
+
+func validateCardID(cardID uint64) error {
+	if cardID == 0 {
+		return errors.New("card_id is required")
+	}
+	if game.GetCardByID(cardID) == nil {
+		return fmt.Errorf("unknown card %d", cardID)
+	}
+	return nil
+}
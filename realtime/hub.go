@@ -2,7 +2,11 @@ package realtime
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"math/rand"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/DCCXXV/orbwars.io/game"
@@ -12,19 +16,71 @@ type Hub struct {
 	Clients map[string]*Client
 
 	World *game.World
+	Mode  game.Mode
+	Guard *Guard
+	Chat  *Chat
 
 	Register   chan *Client
 	Unregister chan *Client
 	Broadcast  chan []byte
+
+	direct   chan directMessage
+	states   chan stateFrame
+	commands chan func(h *Hub)
+
+	snapshot          atomic.Pointer[Snapshot]
+	lastBroadcastTick uint64
+}
+
+type stateFrame struct {
+	snapshot *Snapshot
+	data     []byte
+}
+
+type Snapshot struct {
+	Tick  uint64
+	State GameStateData
+}
+
+type directMessage struct {
+	playerID string
+	data     []byte
 }
 
 func NewHub(world *game.World) *Hub {
 	return &Hub{
 		Clients:    make(map[string]*Client),
 		World:      world,
+		Guard:      NewGuard(4, nil),
+		Chat:       NewChat(),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
 		Broadcast:  make(chan []byte, 256),
+		direct:     make(chan directMessage, 256),
+		states:     make(chan stateFrame, 1),
+		commands:   make(chan func(h *Hub), 256),
+	}
+}
+
+func (h *Hub) SetMode(mode game.Mode) {
+	h.Mode = mode
+	mode.SetEventHandler(h.broadcastModeEvent)
+}
+
+func (h *Hub) broadcastModeEvent(eventType string, data any) {
+	msg, err := json.Marshal(ServerMessage{
+		Type: eventType,
+		Data: data,
+	})
+	if err != nil {
+		log.Printf("Error serializing %s event: %v", eventType, err)
+		return
+	}
+
+	select {
+	case h.Broadcast <- msg:
+	default:
+		log.Printf("Broadcast queue full, dropping %s event", eventType)
 	}
 }
 
@@ -36,157 +92,335 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.Register:
 			h.Clients[client.ID] = client
-			h.World.AddPlayer(client.ID)
-			log.Printf("Player joined %s (%d total)", client.ID, len(h.Clients))
+			playerID := client.ID
+			if client.Spectator {
+				playerID = ""
+				log.Printf("Spectator joined %s (%d total)", client.ID, len(h.Clients))
+			} else {
+				h.Chat.inheritMute(client)
+				cardSeed := rand.Int63()
+				h.World.AddPlayerWithSeed(client.ID, cardSeed)
+				log.Printf("Player joined %s with card seed %d (%d total)", client.ID, cardSeed, len(h.Clients))
+			}
 
 			welcomeMsg := ServerMessage{
-				Type: "welcome",
-				Data: map[string]string{"player_id": client.ID},
+				Type: MsgWelcome,
+				Data: WelcomeData{
+					PlayerID:  playerID,
+					Spectator: client.Spectator,
+					Version:   ProtocolVersion,
+					Features:  client.Features,
+				},
 			}
 			if data, err := json.Marshal(welcomeMsg); err == nil {
 				client.Send <- data
 			}
 
-		case client := <-h.Unregister:
-			if _, ok := h.Clients[client.ID]; ok {
-				delete(h.Clients, client.ID)
-				close(client.Send)
-				h.World.RemovePlayer(client.ID)
-				log.Printf("Player disconnected: %s (%d left)", client.ID, len(h.Clients))
+			mapMsg := ServerMessage{
+				Type: MsgMap,
+				Data: h.World.Map,
 			}
+			if data, err := json.Marshal(mapMsg); err == nil {
+				client.Send <- data
+			}
+
+			h.sendChatHistory(client)
+
+		case client := <-h.Unregister:
+			h.disconnect(client, "connection closed")
 
 		case message := <-h.Broadcast:
 			for _, client := range h.Clients {
 				select {
 				case client.Send <- message:
 				default:
-					close(client.Send)
-					delete(h.Clients, client.ID)
+					h.disconnect(client, "send buffer full")
+				}
+			}
+
+		case frame := <-h.states:
+			for _, client := range h.Clients {
+				if !client.queueState(frame) {
+					continue
+				}
+				if client.lag >= maxStateLag {
+					h.disconnect(client, "too slow to receive game state")
+				}
+			}
+
+		case msg := <-h.direct:
+			if client, ok := h.Clients[msg.playerID]; ok {
+				select {
+				case client.Send <- msg.data:
+				default:
+					h.disconnect(client, "send buffer full")
 				}
 			}
 
+		case cmd := <-h.commands:
+			cmd(h)
+
 		case <-cardCheckTicker.C:
-			h.checkCardOffers()
+			h.World.Submit(h.checkCardOffers)
 		}
 	}
 }
 
-func (h *Hub) HandleMessage(client *Client, msg ClientMessage) {
-	switch msg.Type {
-	case "input":
-		data, err := json.Marshal(msg.Data)
-		if err != nil {
-			return
-		}
+func (h *Hub) Submit(cmd func(h *Hub)) {
+	h.commands <- cmd
+}
 
-		var input game.PlayerInput
-		if err := json.Unmarshal(data, &input); err != nil {
-			log.Println("Error parsing input", err)
-			return
-		}
+func (h *Hub) deliver(client *Client, data []byte) {
+	if data == nil {
+		return
+	}
 
-		h.World.SetPlayerInput(client.ID, input)
+	select {
+	case client.Send <- data:
+	default:
+		h.disconnect(client, "send buffer full")
+	}
+}
 
-	case "card_choice":
-		data, err := json.Marshal(msg.Data)
-		if err != nil {
-			log.Println("Error parsing card choice:", err)
-			return
-		}
+func (h *Hub) disconnect(client *Client, reason string) {
+	if h.Clients[client.ID] != client {
+		return
+	}
+
+	delete(h.Clients, client.ID)
+	h.Chat.carryMute(client)
+	close(client.Send)
+	if client.Conn != nil {
+		client.Conn.Close()
+	}
+	if !client.Spectator {
+		h.World.RemovePlayer(client.ID)
+	}
+	client.release()
+	log.Printf("Player disconnected: %s, %s (%d left)", client.ID, reason, len(h.Clients))
+}
+
+func (h *Hub) HandleMessage(client *Client, msg ClientMessage) error {
+	handler, ok := handlers[msg.Type]
+	if !ok {
+		return fmt.Errorf("unknown message type %q", msg.Type)
+	}
 
-		var choice CardChoiceMessage
-		if err := json.Unmarshal(data, &choice); err != nil {
-			log.Println("Error unmarshaling card choice:", err)
-			return
+	if client.Spectator != spectatorMessages[msg.Type] {
+		if client.Spectator {
+			return fmt.Errorf("spectators cannot send %s", msg.Type)
 		}
+		return fmt.Errorf("%s is only available to spectators", msg.Type)
+	}
 
-		h.HandleCardChoice(client.ID, choice.CardID)
-		log.Printf("Card %d selected by: %s", choice.CardID, client.ID)
+	return handler(h, client, msg.Data)
+}
+
+func (h *Hub) sendError(playerID, msgType string, err error) {
+	h.sendToClient(playerID, ServerMessage{
+		Type: MsgError,
+		Data: ErrorData{
+			Type:    msgType,
+			Message: err.Error(),
+		},
+	})
+}
+
+func errorMessage(msgType string, err error) []byte {
+	data, marshalErr := json.Marshal(ServerMessage{
+		Type: MsgError,
+		Data: ErrorData{
+			Type:    msgType,
+			Message: err.Error(),
+		},
+	})
+	if marshalErr != nil {
+		return nil
 	}
+	return data
 }
 
 func (h *Hub) HandleCardChoice(playerID string, cardID uint64) {
-	h.World.Mu.Lock()
-	defer h.World.Mu.Unlock()
+	h.World.Submit(func(w *game.World) {
+		h.applyCardChoice(w, playerID, cardID)
+	})
+}
 
-	player, ok := h.World.Players[playerID]
+func (h *Hub) applyCardChoice(w *game.World, playerID string, cardID uint64) {
+	player, ok := w.Players[playerID]
 	if !ok {
 		log.Printf("Player %s not found for card choice", playerID)
 		return
 	}
 
+	if !player.IsCardOffered(cardID) {
+		log.Printf("Card %d was not offered to player %s", cardID, playerID)
+		return
+	}
+
 	card := game.GetCardByID(cardID)
 	if card == nil {
 		log.Printf("Card %d not found", cardID)
 		return
 	}
 
-	for _, effect := range card.Effects {
-		player.ApplyCardEffect(effect)
-	}
-
-	player.AppliedCards = append(player.AppliedCards, card.Name)
-	player.CalculateSetBonuses()
-	player.UpdateNextCardScore()
+	player.ApplyCard(card)
 
 	log.Printf("Player %s applied card '%s', next threshold: %d",
 		playerID, card.Name, player.NextCardScore)
 }
 
-func (h *Hub) CheckAndOfferCards(playerID string) {
-	h.World.Mu.Lock()
-	player, ok := h.World.Players[playerID]
+func (h *Hub) HandleCardReroll(playerID string) {
+	h.World.Submit(func(w *game.World) {
+		h.rerollCards(w, playerID)
+	})
+}
+
+func (h *Hub) rerollCards(w *game.World, playerID string) {
+	player, ok := w.Players[playerID]
+	if !ok {
+		log.Printf("Player %s not found for card reroll", playerID)
+		return
+	}
+
+	if !player.CanReroll() {
+		log.Printf("Player %s has no rerolls left", playerID)
+		return
+	}
+
+	player.Rerolls--
+	cards := player.RollCardOffer(3)
+
+	log.Printf("Player %s rerolled card offer (%d rerolls left)", playerID, player.Rerolls)
+	h.sendCardOffer(playerID, cards, player.Rerolls, player.CardOfferRemaining)
+}
+
+func (h *Hub) HandleCardBanish(playerID string, cardID uint64) {
+	h.World.Submit(func(w *game.World) {
+		h.banishCard(w, playerID, cardID)
+	})
+}
+
+func (h *Hub) banishCard(w *game.World, playerID string, cardID uint64) {
+	player, ok := w.Players[playerID]
 	if !ok {
-		h.World.Mu.Unlock()
+		log.Printf("Player %s not found for card banish", playerID)
+		return
+	}
+
+	if !player.IsCardOffered(cardID) || !player.CanReroll() {
+		log.Printf("Player %s cannot banish card %d", playerID, cardID)
 		return
 	}
 
-	if !player.ShouldOfferCards() {
-		h.World.Mu.Unlock()
+	card := game.GetCardByID(cardID)
+	if card == nil {
+		log.Printf("Card %d not found", cardID)
 		return
 	}
 
+	player.Rerolls--
+	player.BanishedCards = append(player.BanishedCards, card.Name)
+	cards := player.RollCardOffer(3)
+
+	log.Printf("Player %s banished card '%s' (%d rerolls left)", playerID, card.Name, player.Rerolls)
+	h.sendCardOffer(playerID, cards, player.Rerolls, player.CardOfferRemaining)
+}
+
+func (h *Hub) offerCards(player *game.Player) {
 	player.CardsPending = true
-	appliedCards := make([]string, len(player.AppliedCards))
-	copy(appliedCards, player.AppliedCards)
-	h.World.Mu.Unlock()
+	cards := player.RollCardOffer(3)
+	if len(cards) == 0 {
+		player.UpdateNextCardScore()
+		log.Printf("No cards left to offer player %s", player.ID)
+		return
+	}
 
-	cards := game.GetRandomCards(3, appliedCards)
+	h.sendCardOffer(player.ID, cards, player.Rerolls, player.CardOfferRemaining)
+}
 
+func (h *Hub) autoPickCard(player *game.Player) {
+	card := player.BestOfferedCard()
+	if card == nil {
+		player.UpdateNextCardScore()
+		log.Printf("Card offer for player %s expired with no valid cards", player.ID)
+		return
+	}
+
+	player.ApplyCard(card)
+
+	log.Printf("Card offer for player %s expired, auto-picked '%s'", player.ID, card.Name)
+
+	h.sendToClient(player.ID, ServerMessage{
+		Type: MsgCardAutoPicked,
+		Data: CardAutoPickedData{
+			Card: *card,
+		},
+	})
+}
+
+func (h *Hub) sendCardOffer(playerID string, cards []game.Card, rerolls int, timeout float64) {
 	if len(cards) == 0 {
 		log.Printf("No cards available for player %s", playerID)
 		return
 	}
 
 	msg := ServerMessage{
-		Type: "card_offer",
+		Type: MsgCardOffer,
 		Data: CardOfferData{
-			Cards: cards,
+			Cards:   cards,
+			Rerolls: rerolls,
+			Timeout: timeout,
 		},
 	}
 
+	h.sendToClient(playerID, msg)
+}
+
+func (h *Hub) sendToClient(playerID string, msg ServerMessage) {
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
-		log.Println("Error marshaling card offer:", err)
+		log.Printf("Error marshaling %s message: %v", msg.Type, err)
 		return
 	}
 
-	if client, ok := h.Clients[playerID]; ok {
-		select {
-		case client.Send <- msgBytes:
-			log.Printf("Card offer sent to player %s", playerID)
-		default:
-			log.Printf("Failed to send card offer to player %s", playerID)
-		}
+	select {
+	case h.direct <- directMessage{playerID: playerID, data: msgBytes}:
+	default:
+		log.Printf("Failed to send %s message to player %s", msg.Type, playerID)
 	}
 }
 
+func (h *Hub) PublishSnapshot() {
+	h.World.Mu.RLock()
+	state := h.buildGameState()
+	h.World.Mu.RUnlock()
+
+	var tick uint64 = 1
+	if previous := h.snapshot.Load(); previous != nil {
+		tick = previous.Tick + 1
+	}
+
+	h.snapshot.Store(&Snapshot{
+		Tick:  tick,
+		State: state,
+	})
+}
+
+func (h *Hub) Snapshot() *Snapshot {
+	return h.snapshot.Load()
+}
+
 func (h *Hub) BroadcastGameState() {
-	state := h.getGameState()
+	snapshot := h.snapshot.Load()
+	if snapshot == nil || snapshot.Tick == h.lastBroadcastTick {
+		return
+	}
+	h.lastBroadcastTick = snapshot.Tick
 
 	msg := ServerMessage{
-		Type: "game_state",
-		Data: state,
+		Type: MsgGameState,
+		Data: snapshot.State,
 	}
 
 	data, err := json.Marshal(msg)
@@ -195,29 +429,28 @@ func (h *Hub) BroadcastGameState() {
 		return
 	}
 
-	h.Broadcast <- data
+	h.queueFrame(stateFrame{snapshot: snapshot, data: data})
 }
 
-func (h *Hub) checkCardOffers() {
-	h.World.Mu.RLock()
-	playersToOffer := make([]string, 0)
+func (h *Hub) queueFrame(frame stateFrame) {
+	select {
+	case <-h.states:
+	default:
+	}
+	h.states <- frame
+}
 
-	for id, p := range h.World.Players {
+func (h *Hub) checkCardOffers(w *game.World) {
+	for _, p := range w.Players {
 		if p.ShouldOfferCards() {
-			playersToOffer = append(playersToOffer, id)
+			h.offerCards(p)
+		} else if p.CardOfferExpired() {
+			h.autoPickCard(p)
 		}
 	}
-	h.World.Mu.RUnlock()
-
-	for _, playerID := range playersToOffer {
-		h.CheckAndOfferCards(playerID)
-	}
 }
 
-func (h *Hub) getGameState() GameStateData {
-	h.World.Mu.RLock()
-	defer h.World.Mu.RUnlock()
-
+func (h *Hub) buildGameState() GameStateData {
 	players := make([]PlayerDTO, 0, len(h.World.Players))
 	for _, p := range h.World.Players {
 		auraData := make([]AuraDTO, len(p.Auras))
@@ -239,6 +472,7 @@ func (h *Hub) getGameState() GameStateData {
 
 		players = append(players, PlayerDTO{
 			ID:            p.ID,
+			TeamID:        p.TeamID,
 			X:             p.X,
 			Y:             p.Y,
 			Size:          p.Size,
@@ -251,9 +485,21 @@ func (h *Hub) getGameState() GameStateData {
 			MaxBarrier:    p.MaxBarrier,
 			NextCardScore: p.NextCardScore,
 			CardsPending:  p.CardsPending,
-			AppliedCards:  p.AppliedCards,
+			Rerolls:       p.Rerolls,
+			CardOfferTime: p.CardOfferRemaining,
+			Kills:         p.Kills,
+			Eliminated:    p.Eliminated,
+			Ping:          p.Latency,
+			Emote:         p.Emote,
+			Emotes:        slices.Clone(p.Emotes),
+			AppliedCards:  slices.Clone(p.AppliedCards),
 			Auras:         auraData,
 			ActiveEffects: effectData,
+
+			Ability:            p.Ability,
+			AbilityCooldown:    p.AbilityCooldown,
+			AbilityMaxCooldown: p.AbilityMaxCooldown(),
+			BurstBarrier:       p.BurstBarrierAmount,
 		})
 	}
 
@@ -261,14 +507,56 @@ func (h *Hub) getGameState() GameStateData {
 	for _, pel := range h.World.Pellets {
 		pellets = append(pellets, PelletDTO{
 			ID:   pel.ID,
+			Type: pel.Type,
 			X:    pel.X,
 			Y:    pel.Y,
 			Size: pel.Size,
 		})
 	}
 
+	projectiles := make([]ProjectileDTO, 0, len(h.World.Projectiles))
+	for _, pr := range h.World.Projectiles {
+		projectiles = append(projectiles, ProjectileDTO{
+			ID:      pr.ID,
+			OwnerID: pr.OwnerID,
+			X:       pr.X,
+			Y:       pr.Y,
+			Size:    pr.Size,
+		})
+	}
+
+	hazards := make([]HazardDTO, 0, len(h.World.Hazards))
+	for _, z := range h.World.Hazards {
+		hazards = append(hazards, HazardDTO{
+			ID:     z.ID,
+			Type:   z.Type,
+			X:      z.X,
+			Y:      z.Y,
+			Radius: z.Radius,
+		})
+	}
+
+	teamScores := h.World.TeamScores()
+	teams := make([]TeamDTO, 0, len(teamScores))
+	for _, t := range teamScores {
+		teams = append(teams, TeamDTO{
+			ID:      t.ID,
+			Score:   t.Score,
+			Players: t.Players,
+		})
+	}
+
+	var match any
+	if h.Mode != nil {
+		match = h.Mode.State()
+	}
+
 	return GameStateData{
-		Players: players,
-		Pellets: pellets,
+		Players:     players,
+		Pellets:     pellets,
+		Projectiles: projectiles,
+		Hazards:     hazards,
+		Match:       match,
+		Teams:       teams,
 	}
 }
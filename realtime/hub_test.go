@@ -0,0 +1,304 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DCCXXV/orbwars.io/game"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	if err := game.LoadCards("../cards.json"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func newTestHub() (*Hub, *game.World) {
+	world := game.NewWorld(game.NewEmptyMap(2000))
+	return NewHub(world), world
+}
+
+func addTestPlayer(t *testing.T, world *game.World, id string) *game.Player {
+	t.Helper()
+
+	world.AddPlayer(id)
+	world.Update(0)
+
+	player, ok := world.Players[id]
+	if !ok {
+		t.Fatalf("player %s was not added", id)
+	}
+	return player
+}
+
+func banishAllCards(player *game.Player) {
+	for id := uint64(0); id < 1000; id++ {
+		if card := game.GetCardByID(id); card != nil {
+			player.BanishedCards = append(player.BanishedCards, card.Name)
+		}
+	}
+}
+
+func TestOfferCardsWithExhaustedPoolAdvancesThreshold(t *testing.T) {
+	hub, world := newTestHub()
+	player := addTestPlayer(t, world, "a")
+	banishAllCards(player)
+
+	player.Score = player.NextCardScore
+	threshold := player.NextCardScore
+
+	hub.checkCardOffers(world)
+
+	if player.CardsPending {
+		t.Fatal("offer with no cards left the player pending")
+	}
+	if player.NextCardScore <= threshold {
+		t.Fatalf("threshold did not advance: %d", player.NextCardScore)
+	}
+}
+
+func TestEmptyPendingOfferExpires(t *testing.T) {
+	hub, world := newTestHub()
+	player := addTestPlayer(t, world, "a")
+
+	player.Score = player.NextCardScore
+	hub.checkCardOffers(world)
+	if !player.CardsPending {
+		t.Fatal("expected a pending offer")
+	}
+
+	banishAllCards(player)
+	player.Rerolls = 1
+	hub.rerollCards(world, "a")
+	if len(player.OfferedCards) != 0 {
+		t.Fatalf("expected an empty reroll, got %v", player.OfferedCards)
+	}
+
+	player.CardOfferRemaining = 0
+	if !player.CardOfferExpired() {
+		t.Fatal("empty pending offer never expires")
+	}
+
+	hub.checkCardOffers(world)
+	if player.CardsPending {
+		t.Fatal("expired empty offer left the player pending")
+	}
+}
+
+func firstCardID(t *testing.T) uint64 {
+	t.Helper()
+
+	for id := uint64(1); id < 1000; id++ {
+		if game.GetCardByID(id) != nil {
+			return id
+		}
+	}
+	t.Fatal("no cards loaded")
+	return 0
+}
+
+func drain(c *Client) {
+	for range c.Send {
+	}
+}
+
+func TestHubConcurrentTraffic(t *testing.T) {
+	hub, world := newTestHub()
+	go hub.Run()
+
+	cardID := firstCardID(t)
+	input := ClientMessage{Type: MsgInput, Data: json.RawMessage(`{"w":true,"d":true}`)}
+	choice := ClientMessage{Type: MsgCardChoice, Data: json.RawMessage(fmt.Sprintf(`{"card_id":%d}`, cardID))}
+	spectate := ClientMessage{Type: MsgSpectate, Data: json.RawMessage(`{"mode":"free","x":100,"y":100}`)}
+
+	stop := make(chan struct{})
+	var loops sync.WaitGroup
+
+	loops.Add(2)
+	go func() {
+		defer loops.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				world.Update(1.0 / 60)
+				hub.PublishSnapshot()
+
+				world.Mu.Lock()
+				for _, p := range world.Players {
+					p.Score += 50
+				}
+				hub.checkCardOffers(world)
+				world.Mu.Unlock()
+			}
+		}
+	}()
+	go func() {
+		defer loops.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				hub.BroadcastGameState()
+			}
+		}
+	}()
+
+	var clients sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		clients.Add(1)
+		go func(i int) {
+			defer clients.Done()
+			for round := 0; round < 10; round++ {
+				client := NewClient(fmt.Sprintf("c%d-%d", i, round), "127.0.0.1", nil, hub)
+				client.Spectator = i == 0
+				go drain(client)
+				hub.Register <- client
+
+				msgs := []ClientMessage{input, choice}
+				if client.Spectator {
+					msgs = []ClientMessage{spectate}
+				}
+				for j := 0; j < 20; j++ {
+					for _, msg := range msgs {
+						if err := hub.HandleMessage(client, msg); err != nil {
+							t.Errorf("%s: %v", msg.Type, err)
+						}
+					}
+				}
+
+				hub.Unregister <- client
+			}
+		}(i)
+	}
+
+	clients.Wait()
+	close(stop)
+	loops.Wait()
+}
+
+func newBenchHub(b *testing.B, players int) (*Hub, *game.World) {
+	b.Helper()
+
+	world := game.NewWorld(game.NewEmptyMap(5000))
+	for i := 0; i < players; i++ {
+		world.AddPlayer(fmt.Sprintf("p%d", i))
+	}
+	world.Update(0)
+
+	for _, p := range world.Players {
+		p.Input = game.PlayerInput{W: true, D: true}
+	}
+	return NewHub(world), world
+}
+
+// benchmarkTick runs the simulation and reports the worst and 99th
+// percentile tick times, which is where broadcast contention shows up.
+func benchmarkTick(b *testing.B, world *game.World, publish func()) {
+	ticks := make([]time.Duration, 0, b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		world.Update(1.0 / 60)
+		publish()
+		ticks = append(ticks, time.Since(start))
+	}
+	b.StopTimer()
+
+	slices.Sort(ticks)
+	b.ReportMetric(float64(ticks[len(ticks)*99/100].Nanoseconds()), "p99-ns/tick")
+	b.ReportMetric(float64(ticks[len(ticks)-1].Nanoseconds()), "max-ns/tick")
+}
+
+func broadcastLoop(stop <-chan struct{}, broadcast func()) {
+	ticker := time.NewTicker(time.Second / 60)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			broadcast()
+		}
+	}
+}
+
+// startConsumers runs the hub with a few spectators so every broadcast is
+// fanned out and turned into per-client views, like a live server.
+func startConsumers(hub *Hub) {
+	go hub.Run()
+
+	for i := 0; i < 4; i++ {
+		client := NewClient(fmt.Sprintf("s%d", i), "127.0.0.1", nil, hub)
+		client.Spectator = true
+		go func() {
+			for {
+				select {
+				case _, ok := <-client.Send:
+					if !ok {
+						return
+					}
+				case frame := <-client.state:
+					client.spectatorView(frame.snapshot)
+				}
+			}
+		}()
+		hub.Register <- client
+	}
+}
+
+func BenchmarkTick(b *testing.B) {
+	hub, world := newBenchHub(b, 50)
+	benchmarkTick(b, world, hub.PublishSnapshot)
+}
+
+// BenchmarkTickWithBroadcast broadcasts the snapshot published at the end of
+// each tick, so the broadcaster never touches the world lock.
+func BenchmarkTickWithBroadcast(b *testing.B) {
+	hub, world := newBenchHub(b, 50)
+	startConsumers(hub)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go broadcastLoop(stop, hub.BroadcastGameState)
+
+	benchmarkTick(b, world, hub.PublishSnapshot)
+}
+
+// BenchmarkTickWithLockedBroadcast builds the state under the world read lock
+// on every broadcast, the way it was done before snapshots. Everything else
+// matches BenchmarkTickWithBroadcast.
+func BenchmarkTickWithLockedBroadcast(b *testing.B) {
+	hub, world := newBenchHub(b, 50)
+	startConsumers(hub)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go broadcastLoop(stop, func() {
+		world.Mu.RLock()
+		snapshot := &Snapshot{State: hub.buildGameState()}
+		world.Mu.RUnlock()
+
+		data, err := json.Marshal(ServerMessage{Type: MsgGameState, Data: snapshot.State})
+		if err != nil {
+			b.Error(err)
+			return
+		}
+		hub.queueFrame(stateFrame{snapshot: snapshot, data: data})
+	})
+
+	benchmarkTick(b, world, func() {})
+}
@@ -1,12 +1,14 @@
 package realtime
 
 import (
+	"encoding/json"
+
 	"github.com/DCCXXV/orbwars.io/game"
 )
 
 type ClientMessage struct {
-	Type string `json:"type"`
-	Data any    `json:"data"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
 }
 
 type InputMessage struct {
@@ -20,6 +22,14 @@ type CardChoiceMessage struct {
 	CardID uint64 `json:"card_id"`
 }
 
+type CardBanishMessage struct {
+	CardID uint64 `json:"card_id"`
+}
+
+type EmoteMessage struct {
+	Emote string `json:"emote"`
+}
+
 type ServerMessage struct {
 	Type string `json:"type"`
 	Data any    `json:"data"`
@@ -43,6 +53,7 @@ type ActiveEffectDTO struct {
 
 type PlayerDTO struct {
 	ID            string            `json:"id"`
+	TeamID        string            `json:"team_id,omitempty"`
 	X             float64           `json:"x"`
 	Y             float64           `json:"y"`
 	Size          int               `json:"size"`
@@ -55,23 +66,69 @@ type PlayerDTO struct {
 	MaxBarrier    int               `json:"max_barrier"`
 	NextCardScore int               `json:"next_card_score"`
 	CardsPending  bool              `json:"cards_pending"`
+	Rerolls       int               `json:"rerolls"`
+	CardOfferTime float64           `json:"card_offer_time"`
+	Kills         int               `json:"kills"`
+	Eliminated    bool              `json:"eliminated"`
+	Ping          int               `json:"ping"`
+	Emote         string            `json:"emote,omitempty"`
+	Emotes        []string          `json:"emotes"`
 	AppliedCards  []string          `json:"applied_cards"`
 	Auras         []AuraDTO         `json:"auras"`
 	ActiveEffects []ActiveEffectDTO `json:"active_effects"`
+
+	Ability            string  `json:"ability"`
+	AbilityCooldown    float64 `json:"ability_cooldown"`
+	AbilityMaxCooldown float64 `json:"ability_max_cooldown"`
+	BurstBarrier       int     `json:"burst_barrier"`
 }
 
 type PelletDTO struct {
 	ID   string  `json:"id"`
+	Type string  `json:"type"`
 	X    float64 `json:"x"`
 	Y    float64 `json:"y"`
 	Size float64 `json:"size"`
 }
 
+type ProjectileDTO struct {
+	ID      string  `json:"id"`
+	OwnerID string  `json:"owner_id"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Size    float64 `json:"size"`
+}
+
+type HazardDTO struct {
+	ID     string  `json:"id"`
+	Type   string  `json:"type"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+}
+
+type TeamDTO struct {
+	ID      string `json:"id"`
+	Score   int    `json:"score"`
+	Players int    `json:"players"`
+}
+
 type GameStateData struct {
-	Players []PlayerDTO `json:"players"`
-	Pellets []PelletDTO `json:"pellets"`
+	Players     []PlayerDTO     `json:"players"`
+	Pellets     []PelletDTO     `json:"pellets"`
+	Projectiles []ProjectileDTO `json:"projectiles"`
+	Hazards     []HazardDTO     `json:"hazards"`
+	Match       any             `json:"match,omitempty"`
+	Teams       []TeamDTO       `json:"teams,omitempty"`
+	Camera      *CameraDTO      `json:"camera,omitempty"`
 }
 
 type CardOfferData struct {
-	Cards []game.Card `json:"cards"`
+	Cards   []game.Card `json:"cards"`
+	Rerolls int         `json:"rerolls"`
+	Timeout float64     `json:"timeout"`
+}
+
+type CardAutoPickedData struct {
+	Card game.Card `json:"card"`
 }
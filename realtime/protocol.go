@@ -0,0 +1,100 @@
+package realtime
+
+import "github.com/DCCXXV/orbwars.io/game"
+
+const (
+	ProtocolVersion    = 1
+	MinProtocolVersion = 1
+)
+
+const (
+	FeatureBinary     = "binary"
+	FeatureDeltas     = "deltas"
+	FeaturePrediction = "prediction"
+)
+
+var supportedFeatures = map[string]bool{
+	FeatureBinary:     false,
+	FeatureDeltas:     false,
+	FeaturePrediction: true,
+}
+
+// Client to server message types. Each one except hello needs a handler
+// registered in handlers.
+const (
+	MsgHello      = "hello"
+	MsgInput      = "input"
+	MsgCardChoice = "card_choice"
+	MsgCardReroll = "card_reroll"
+	MsgCardBanish = "card_banish"
+	MsgAbility    = "ability"
+	MsgSpectate   = "spectate"
+	MsgChat       = "chat"
+	MsgChatBlock  = "chat_block"
+	MsgEmote      = "emote"
+)
+
+// Server to client message types.
+const (
+	MsgWelcome         = "welcome"
+	MsgVersionMismatch = "version_mismatch"
+	MsgError           = "error"
+	MsgMap             = "map"
+	MsgGameState       = "game_state"
+	MsgCardOffer       = "card_offer"
+	MsgCardAutoPicked  = "card_auto_picked"
+	MsgMatchPhase      = "match_phase"
+	MsgKothUpdate      = "koth_update"
+	MsgOrbEvent        = "orb_event"
+	MsgChatHistory     = "chat_history"
+)
+
+// ServerMessageTypes maps every message the server may send to its payload.
+// Mode events carry whatever the active game.Mode returns from State.
+var ServerMessageTypes = map[string]any{
+	MsgWelcome:         WelcomeData{},
+	MsgVersionMismatch: VersionMismatchData{},
+	MsgError:           ErrorData{},
+	MsgMap:             game.Map{},
+	MsgGameState:       GameStateData{},
+	MsgCardOffer:       CardOfferData{},
+	MsgCardAutoPicked:  CardAutoPickedData{},
+	MsgMatchPhase:      nil,
+	MsgKothUpdate:      game.KingOfTheHillState{},
+	MsgOrbEvent:        game.OrbEvent{},
+	MsgChat:            ChatData{},
+	MsgChatHistory:     ChatHistoryData{},
+}
+
+type HelloMessage struct {
+	Version  int      `json:"version"`
+	Features []string `json:"features"`
+}
+
+type WelcomeData struct {
+	PlayerID  string   `json:"player_id"`
+	Spectator bool     `json:"spectator,omitempty"`
+	Version   int      `json:"version"`
+	Features  []string `json:"features"`
+}
+
+type ErrorData struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type VersionMismatchData struct {
+	ClientVersion int `json:"client_version"`
+	ServerVersion int `json:"server_version"`
+	MinVersion    int `json:"min_version"`
+}
+
+func negotiateFeatures(requested []string) []string {
+	features := make([]string, 0, len(requested))
+	for _, feature := range requested {
+		if supportedFeatures[feature] {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
@@ -0,0 +1,82 @@
+package realtime
+
+import "time"
+
+type TokenBucket struct {
+	Rate  float64
+	Burst float64
+
+	tokens float64
+	last   time.Time
+}
+
+type rateLimit struct {
+	rate  float64
+	burst float64
+}
+
+var messageLimits = map[string]rateLimit{
+	"input":       {rate: 90, burst: 120},
+	"card_choice": {rate: 4, burst: 8},
+	"card_reroll": {rate: 4, burst: 8},
+	"card_banish": {rate: 4, burst: 8},
+	"ability":     {rate: 10, burst: 10},
+	"chat":        {rate: 0.5, burst: 4},
+	"chat_block":  {rate: 2, burst: 10},
+	"emote":       {rate: 0.5, burst: 3},
+	"spectate":    {rate: 10, burst: 20},
+}
+
+// Types without their own limit share one bucket, so made-up types can't
+// grow a client's limits map.
+const unknownMessages = ""
+
+var defaultMessageLimit = rateLimit{rate: 10, burst: 20}
+
+const (
+	abuseRate  = 2.0
+	abuseBurst = 40.0
+)
+
+func NewTokenBucket(rate, burst float64) *TokenBucket {
+	return &TokenBucket{
+		Rate:   rate,
+		Burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Allow(now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.Rate
+	if b.tokens > b.Burst {
+		b.tokens = b.Burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (c *Client) allowMessage(msgType string) bool {
+	limit, known := messageLimits[msgType]
+	if !known {
+		msgType = unknownMessages
+		limit = defaultMessageLimit
+	}
+
+	bucket, ok := c.limits[msgType]
+	if !ok {
+		bucket = NewTokenBucket(limit.rate, limit.burst)
+		c.limits[msgType] = bucket
+	}
+
+	return bucket.Allow(time.Now())
+}
+
+func (c *Client) strike() bool {
+	return c.abuse.Allow(time.Now())
+}
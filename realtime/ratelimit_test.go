@@ -0,0 +1,29 @@
+package realtime
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUnknownMessageTypesShareABucket(t *testing.T) {
+	hub, _ := newTestHub()
+	client := NewClient("a", "1.1.1.1", nil, hub)
+
+	allowed := 0
+	for i := 0; i < 1000; i++ {
+		if client.allowMessage(fmt.Sprintf("made_up_%d", i)) {
+			allowed++
+		}
+	}
+
+	if len(client.limits) != 1 {
+		t.Fatalf("got %d buckets for unknown types, want 1", len(client.limits))
+	}
+	if allowed > int(defaultMessageLimit.burst)+1 {
+		t.Fatalf("allowed %d unknown messages, want about %v", allowed, defaultMessageLimit.burst)
+	}
+
+	if !client.allowMessage(MsgInput) {
+		t.Fatal("unknown types used up the input bucket")
+	}
+}
@@ -0,0 +1,140 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+)
+
+const spectatorViewRadius = 2500.0
+
+const (
+	CameraLeader = "leader"
+	CameraFollow = "follow"
+	CameraFree   = "free"
+)
+
+type Camera struct {
+	Mode     string
+	FollowID string
+	X        float64
+	Y        float64
+}
+
+type SpectateMessage struct {
+	Mode     string  `json:"mode"`
+	FollowID string  `json:"follow_id"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+}
+
+type CameraDTO struct {
+	Mode     string  `json:"mode"`
+	FollowID string  `json:"follow_id,omitempty"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+}
+
+func (m *SpectateMessage) Validate() error {
+	switch m.Mode {
+	case CameraLeader:
+	case CameraFollow:
+		if m.FollowID == "" {
+			return errors.New("follow_id is required to follow a player")
+		}
+	case CameraFree:
+		if math.IsNaN(m.X) || math.IsInf(m.X, 0) || math.IsNaN(m.Y) || math.IsInf(m.Y, 0) {
+			return errors.New("camera position must be finite")
+		}
+	default:
+		return errors.New("mode must be leader, follow or free")
+	}
+	return nil
+}
+
+func (c *Client) SetCamera(camera Camera) {
+	c.camera.Store(&camera)
+}
+
+func (c *Client) spectatorView(snapshot *Snapshot) []byte {
+	camera := Camera{Mode: CameraLeader}
+	if stored := c.camera.Load(); stored != nil {
+		camera = *stored
+	}
+
+	state := snapshot.State
+	view := CameraDTO{Mode: camera.Mode, X: camera.X, Y: camera.Y}
+
+	var target *PlayerDTO
+	switch camera.Mode {
+	case CameraFollow:
+		target = findPlayer(state.Players, camera.FollowID)
+		if target == nil {
+			target = leadingPlayer(state.Players)
+		}
+	case CameraLeader:
+		target = leadingPlayer(state.Players)
+	}
+	if target != nil {
+		view.FollowID = target.ID
+		view.X = target.X
+		view.Y = target.Y
+	}
+
+	pellets := make([]PelletDTO, 0, len(state.Pellets)/4)
+	for _, pellet := range state.Pellets {
+		if inView(view, pellet.X, pellet.Y) {
+			pellets = append(pellets, pellet)
+		}
+	}
+
+	projectiles := make([]ProjectileDTO, 0, len(state.Projectiles))
+	for _, projectile := range state.Projectiles {
+		if inView(view, projectile.X, projectile.Y) {
+			projectiles = append(projectiles, projectile)
+		}
+	}
+
+	state.Pellets = pellets
+	state.Projectiles = projectiles
+	state.Camera = &view
+
+	data, err := json.Marshal(ServerMessage{
+		Type: MsgGameState,
+		Data: state,
+	})
+	if err != nil {
+		log.Println("Error serializing spectator state:", err)
+		return nil
+	}
+	return data
+}
+
+func inView(view CameraDTO, x, y float64) bool {
+	dx := x - view.X
+	dy := y - view.Y
+	return dx*dx+dy*dy <= spectatorViewRadius*spectatorViewRadius
+}
+
+func findPlayer(players []PlayerDTO, id string) *PlayerDTO {
+	for i := range players {
+		if players[i].ID == id {
+			return &players[i]
+		}
+	}
+	return nil
+}
+
+func leadingPlayer(players []PlayerDTO) *PlayerDTO {
+	var leader *PlayerDTO
+	for i := range players {
+		if players[i].Eliminated {
+			continue
+		}
+		if leader == nil || players[i].Score > leader.Score {
+			leader = &players[i]
+		}
+	}
+	return leader
+}
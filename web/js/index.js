@@ -448,10 +448,21 @@ import { Renderer } from "./renderer.js";
     }
 
     let cardsToSpawn = [];
+    let cardTimerText = null;
 
-    function showCardSelection(cards) {
+    function clearCardSelection() {
         cardsToSpawn.forEach((c) => app.stage.removeChild(c));
         cardsToSpawn = [];
+        cardTimerText = null;
+    }
+
+    function updateCardTimer(remaining) {
+        if (!cardTimerText) return;
+        cardTimerText.text = `${Math.max(0, Math.ceil(remaining))}s`;
+    }
+
+    function showCardSelection(cards, rerolls, timeout) {
+        clearCardSelection();
 
         const cardWidth = 300;
         const cardHeight = 500;
@@ -558,15 +569,79 @@ import { Renderer } from "./renderer.js";
             cardContainer.cursor = "pointer";
             cardContainer.on("pointerdown", () => {
                 network.sendCardChoice(card.id);
-                cardsToSpawn.forEach((c) => app.stage.removeChild(c));
-                cardsToSpawn = [];
+                clearCardSelection();
             });
 
+            if (rerolls > 0) {
+                const banishButton = new Text({
+                    text: "✕",
+                    style: {
+                        fontFamily: "Virgil",
+                        fontSize: 24,
+                        fill: 0x999999,
+                    },
+                });
+                banishButton.position.set(cardWidth - 36, 12);
+                banishButton.eventMode = "static";
+                banishButton.cursor = "pointer";
+                banishButton.on("pointerdown", (e) => {
+                    e.stopPropagation();
+                    network.sendCardBanish(card.id);
+                });
+                cardContainer.addChild(banishButton);
+            }
+
             app.stage.addChild(cardContainer);
             cardsToSpawn.push(cardContainer);
 
             offsetX += cardWidth + spacing;
         });
+
+        if (rerolls > 0) {
+            const rerollButton = new Text({
+                text: `REROLL (${rerolls})`,
+                style: {
+                    fontFamily: "Virgil",
+                    fontSize: 24,
+                    fill: 0x7777cc,
+                    fontWeight: "bold",
+                },
+            });
+            rerollButton.anchor.set(0.5, 0);
+            rerollButton.position.set(
+                app.renderer.width / 2,
+                app.renderer.height / 2 + cardHeight / 2 + spacing,
+            );
+            rerollButton.eventMode = "static";
+            rerollButton.cursor = "pointer";
+            rerollButton.on("pointerdown", () => {
+                network.sendCardReroll();
+            });
+
+            app.stage.addChild(rerollButton);
+            cardsToSpawn.push(rerollButton);
+        }
+
+        if (timeout > 0) {
+            cardTimerText = new Text({
+                text: "",
+                style: {
+                    fontFamily: "Virgil",
+                    fontSize: 28,
+                    fill: 0xcc7777,
+                    fontWeight: "bold",
+                },
+            });
+            cardTimerText.anchor.set(0.5, 1);
+            cardTimerText.position.set(
+                app.renderer.width / 2,
+                app.renderer.height / 2 - cardHeight / 2 - spacing,
+            );
+            updateCardTimer(timeout);
+
+            app.stage.addChild(cardTimerText);
+            cardsToSpawn.push(cardTimerText);
+        }
     }
 
     const keys = {
@@ -576,9 +651,112 @@ import { Renderer } from "./renderer.js";
         d: false,
     };
 
+    let myEmotes = [];
+
+    const chatLog = document.createElement("div");
+    chatLog.style.cssText =
+        "position:absolute;left:20px;bottom:60px;width:360px;font-family:Virgil;font-size:14px;color:#333;pointer-events:none;white-space:pre-wrap;";
+    document.body.appendChild(chatLog);
+
+    const chatInput = document.createElement("input");
+    chatInput.maxLength = 200;
+    chatInput.placeholder = "ENTER to chat, /t team, /block id, /unblock id";
+    chatInput.style.cssText =
+        "position:absolute;left:20px;bottom:20px;width:360px;font-family:Virgil;font-size:14px;display:none;";
+    document.body.appendChild(chatInput);
+
+    const chatLines = [];
+    const chatPlayers = new Map();
+
+    function pushChatLine(line) {
+        chatLines.push(line);
+        while (chatLines.length > 8) chatLines.shift();
+        chatLog.textContent = chatLines.join("\n");
+    }
+
+    function addChatLine(entry) {
+        const name = entry.player_id.substring(0, 8);
+        chatPlayers.set(name, entry.player_id);
+        const prefix = entry.team_only ? "[TEAM] " : "";
+        pushChatLine(`${prefix}${name}: ${entry.text}`);
+    }
+
+    const orbEventVerbs = {
+        pickup: "picked up",
+        drop: "dropped",
+        capture: "captured",
+        return: "returned",
+    };
+
+    function addOrbEventLine(event) {
+        const verb = orbEventVerbs[event.type];
+        if (!verb) return;
+
+        const team = event.team.toUpperCase();
+        if (!event.player_id) {
+            pushChatLine(`* ${team} orb ${verb}`);
+            return;
+        }
+
+        const who =
+            event.player_id === network.myPlayerID
+                ? "YOU"
+                : event.player_id.substring(0, 8);
+        const orb =
+            event.type === "capture" ? `an orb for ${team}` : `the ${team} orb`;
+        pushChatLine(`* ${who} ${verb} ${orb}`);
+    }
+
+    function submitChat() {
+        const text = chatInput.value.trim();
+        chatInput.value = "";
+        chatInput.style.display = "none";
+        chatInput.blur();
+        if (!text) return;
+
+        const [command, target] = text.split(/\s+/, 2);
+        if (command === "/block" || command === "/unblock") {
+            const playerID = chatPlayers.get(target) || target;
+            if (playerID) network.sendChatBlock(playerID, command === "/block");
+            return;
+        }
+        if (command === "/t") {
+            const teamText = text.slice(command.length).trim();
+            if (teamText) network.sendChat(teamText, "team");
+            return;
+        }
+        network.sendChat(text);
+    }
+
+    chatInput.addEventListener("keydown", (e) => {
+        e.stopPropagation();
+        if (e.key === "Enter") submitChat();
+        if (e.key === "Escape") {
+            chatInput.value = "";
+            chatInput.style.display = "none";
+            chatInput.blur();
+        }
+    });
+
     window.addEventListener("keydown", (e) => {
+        if (network.spectating) return;
+
+        if (e.key === "Enter" && document.activeElement !== chatInput) {
+            for (const key in keys) keys[key] = false;
+            chatInput.style.display = "block";
+            chatInput.focus();
+            e.preventDefault();
+            return;
+        }
+
         const key = e.key.toLowerCase();
         if (key in keys) keys[key] = true;
+        if (key === " " && !e.repeat) network.sendAbility();
+
+        const emoteIndex = Number.parseInt(key, 10) - 1;
+        if (!e.repeat && emoteIndex >= 0 && emoteIndex < myEmotes.length) {
+            network.sendEmote(myEmotes[emoteIndex]);
+        }
     });
 
     window.addEventListener("keyup", (e) => {
@@ -670,9 +848,29 @@ import { Renderer } from "./renderer.js";
 
     let leaderPlayer = null;
 
+    const spectatorCamera = {
+        mode: "leader",
+        x: 0,
+        y: 0,
+        followIndex: 0,
+        players: [],
+    };
+
     const network = new NetworkManager(
         (gameState) => {
             renderer.render(gameState);
+            if (network.spectating && gameState.camera) {
+                spectatorCamera.players = gameState.players.map((p) => p.id);
+                if (spectatorCamera.mode !== "free") {
+                    spectatorCamera.x = gameState.camera.x;
+                    spectatorCamera.y = gameState.camera.y;
+                }
+            }
+            const myServerPlayer = gameState.players.find(
+                (p) => p.id === network.myPlayerID,
+            );
+            updateMatchOverlay(gameState.match, myServerPlayer);
+            updateTeamScores(gameState.teams, myServerPlayer);
 
             if (network.myPlayerID) {
                 const serverPlayer = gameState.players.find(
@@ -721,8 +919,13 @@ import { Renderer } from "./renderer.js";
                     localPlayer.damage = serverPlayer.damage;
                     localPlayer.appliedCards = serverPlayer.applied_cards || [];
                     localPlayer.size = serverPlayer.size;
-                    localPlayer.barrier = serverPlayer.barrier || 0;
-                    localPlayer.maxBarrier = serverPlayer.max_barrier || 0;
+                    localPlayer.barrier =
+                        (serverPlayer.barrier || 0) +
+                        (serverPlayer.burst_barrier || 0);
+                    localPlayer.maxBarrier = Math.max(
+                        serverPlayer.max_barrier || 0,
+                        localPlayer.barrier,
+                    );
                     localPlayer.auras = serverPlayer.auras || [];
                     localPlayer.active_effects =
                         serverPlayer.active_effects || [];
@@ -733,6 +936,13 @@ import { Renderer } from "./renderer.js";
                         localPlayer.maxBarrier,
                     );
                     updateSetProgress(localPlayer.appliedCards);
+                    updateAbilityText(
+                        serverPlayer.ability,
+                        serverPlayer.ability_cooldown || 0,
+                    );
+                    updateCardTimer(serverPlayer.card_offer_time || 0);
+                    updatePingText(serverPlayer.ping || 0);
+                    myEmotes = serverPlayer.emotes || [];
                 }
 
                 const topPlayer = updateLeaderboard(
@@ -773,11 +983,22 @@ import { Renderer } from "./renderer.js";
                 }
             }
         },
-        (cards) => {
-            showCardSelection(cards);
+        (cards, rerolls, timeout) => {
+            showCardSelection(cards, rerolls, timeout);
+        },
+        (card) => {
+            console.log("card auto-picked:", card.name);
+            clearCardSelection();
         },
     );
 
+    network.onMap = (gameMap) => {
+        renderer.renderMap(gameMap);
+    };
+
+    network.onChat = (entry) => addChatLine(entry);
+    network.onOrbEvent = (event) => addOrbEventLine(event);
+
     network.connect();
 
     function updateLocalPlayer() {
@@ -830,6 +1051,11 @@ import { Renderer } from "./renderer.js";
 
     app.ticker = new Ticker();
     app.ticker.add(() => {
+        if (network.spectating) {
+            updateSpectatorCamera();
+            return;
+        }
+
         updateLocalPlayer();
         renderer.renderLocalPlayer(localPlayer, localPlayer.appliedCards || []);
         updateCameraLocal();
@@ -856,6 +1082,54 @@ import { Renderer } from "./renderer.js";
 
     app.ticker.start();
 
+    let lastSpectateSend = 0;
+
+    function updateSpectatorCamera() {
+        const panSpeed = 15;
+        const moving = keys.w || keys.a || keys.s || keys.d;
+        if (moving) {
+            spectatorCamera.mode = "free";
+            if (keys.w) spectatorCamera.y -= panSpeed;
+            if (keys.s) spectatorCamera.y += panSpeed;
+            if (keys.a) spectatorCamera.x -= panSpeed;
+            if (keys.d) spectatorCamera.x += panSpeed;
+
+            const now = Date.now();
+            if (now - lastSpectateSend > 100) {
+                network.sendSpectate(
+                    "free",
+                    "",
+                    spectatorCamera.x,
+                    spectatorCamera.y,
+                );
+                lastSpectateSend = now;
+            }
+        }
+
+        world.scale.set(0.6);
+        world.x = -spectatorCamera.x * 0.6 + app.renderer.width / 2;
+        world.y = -spectatorCamera.y * 0.6 + app.renderer.height / 2;
+    }
+
+    window.addEventListener("keydown", (e) => {
+        if (!network.spectating) return;
+
+        const key = e.key.toLowerCase();
+        if (key === "l") {
+            spectatorCamera.mode = "leader";
+            network.sendSpectate("leader");
+        } else if (key === "n" && spectatorCamera.players.length > 0) {
+            spectatorCamera.followIndex =
+                (spectatorCamera.followIndex + 1) %
+                spectatorCamera.players.length;
+            spectatorCamera.mode = "follow";
+            network.sendSpectate(
+                "follow",
+                spectatorCamera.players[spectatorCamera.followIndex],
+            );
+        }
+    });
+
     function updateCameraLocal() {
         if (!network.myPlayerID) return;
 
@@ -921,6 +1195,193 @@ import { Renderer } from "./renderer.js";
         barrierText.anchor.set(0.5);
     }
 
+    const abilityText = new Text({
+        text: "",
+        style: {
+            fontFamily: "Virgil",
+            fontSize: 20,
+            fill: 0x333333,
+            align: "center",
+        },
+    });
+    abilityText.anchor.set(0.5, 1);
+    app.stage.addChild(abilityText);
+
+    function updateAbilityText(ability, cooldown) {
+        if (!ability) {
+            abilityText.visible = false;
+            return;
+        }
+
+        abilityText.visible = true;
+        abilityText.position.set(
+            app.renderer.width / 2,
+            app.renderer.height - 110,
+        );
+
+        const name = ability.replace("_", " ").toUpperCase();
+        abilityText.text =
+            cooldown > 0
+                ? `${name}: ${cooldown.toFixed(1)}s`
+                : `${name}: READY (SPACE)`;
+    }
+
+    const pingText = new Text({
+        text: "",
+        style: {
+            fontFamily: "Virgil",
+            fontSize: 16,
+            fill: 0x777777,
+        },
+    });
+    pingText.anchor.set(1, 1);
+    app.stage.addChild(pingText);
+
+    function updatePingText(ping) {
+        pingText.position.set(app.renderer.width - 20, app.renderer.height - 20);
+        pingText.text = `PING: ${ping} ms`;
+    }
+
+    const teamScoreText = new Text({
+        text: "",
+        style: {
+            fontFamily: "Virgil",
+            fontSize: 20,
+            fill: 0x333333,
+            align: "right",
+        },
+    });
+    teamScoreText.anchor.set(1, 1);
+    app.stage.addChild(teamScoreText);
+
+    function updateTeamScores(teams, myPlayer) {
+        if (!teams || teams.length === 0) {
+            teamScoreText.visible = false;
+            return;
+        }
+
+        teamScoreText.visible = true;
+        teamScoreText.position.set(
+            app.renderer.width - 20,
+            app.renderer.height - 20,
+        );
+        teamScoreText.text = teams
+            .map((t) => {
+                const mine = myPlayer && myPlayer.team_id === t.id ? " *" : "";
+                return `${t.id.toUpperCase()} (${t.players})  ${t.score}${mine}`;
+            })
+            .join("\n");
+    }
+
+    const matchText = new Text({
+        text: "",
+        style: {
+            fontFamily: "Virgil",
+            fontSize: 24,
+            fill: 0x333333,
+            align: "center",
+            fontWeight: "bold",
+        },
+    });
+    matchText.anchor.set(0.5, 0);
+    app.stage.addChild(matchText);
+
+    function updateMatchOverlay(match, myPlayer) {
+        if (!match) {
+            matchText.visible = false;
+            return;
+        }
+
+        matchText.visible = true;
+        matchText.position.set(app.renderer.width / 2, 20);
+
+        const seconds = Math.max(0, Math.ceil(match.time_remaining));
+
+        if (match.mode === "koth") {
+            updateKingOfTheHillOverlay(match, myPlayer, seconds);
+            return;
+        }
+
+        if (match.mode === "cto") {
+            updateCaptureTheOrbOverlay(match, myPlayer, seconds);
+            return;
+        }
+
+        switch (match.phase) {
+            case "lobby":
+                matchText.text = "WAITING FOR PLAYERS";
+                break;
+            case "countdown":
+                matchText.text = `MATCH STARTS IN ${seconds}`;
+                break;
+            case "running": {
+                const status =
+                    myPlayer && myPlayer.eliminated ? "ELIMINATED - " : "";
+                matchText.text = `${status}${match.alive} ALIVE - ZONE ${seconds}s`;
+                break;
+            }
+            case "finished": {
+                const lines = (match.results || [])
+                    .slice(0, 5)
+                    .map(
+                        (r) =>
+                            `#${r.placement}  ${r.player_id.substring(0, 12)}  ${r.kills} kills`,
+                    );
+                const winner = match.winner_id
+                    ? match.winner_id.substring(0, 12)
+                    : "nobody";
+                matchText.text = [`WINNER: ${winner}`, ...lines].join("\n");
+                break;
+            }
+        }
+    }
+
+    function updateCaptureTheOrbOverlay(match, myPlayer, seconds) {
+        if (match.phase === "finished") {
+            const winner = match.winner_id
+                ? match.winner_id.toUpperCase()
+                : "DRAW";
+            matchText.text = `ORB CAPTURE: ${winner}`;
+            return;
+        }
+
+        const score = match.bases
+            .map((b) => `${b.team.toUpperCase()} ${match.points[b.team] || 0}`)
+            .join(" - ");
+        const carrying =
+            myPlayer && match.orbs.some((o) => o.carrier_id === myPlayer.id)
+                ? "\nCARRYING ORB - RETURN TO BASE"
+                : "";
+        matchText.text = `${score}  (FIRST TO ${match.captures_to_win})  -  ${seconds}s${carrying}`;
+    }
+
+    function updateKingOfTheHillOverlay(match, myPlayer, seconds) {
+        const mySide =
+            myPlayer && (myPlayer.team_id ? myPlayer.team_id : myPlayer.id);
+        const sideLabel = (side) =>
+            side === mySide ? "YOU" : side.substring(0, 12);
+
+        if (match.phase === "finished") {
+            const winner = match.winner_id
+                ? sideLabel(match.winner_id)
+                : "DRAW";
+            matchText.text = `KING OF THE HILL: ${winner}`;
+            return;
+        }
+
+        const zone = match.zone;
+        let status = "UNCLAIMED";
+        if (zone.contested) {
+            status = "CONTESTED";
+        } else if (zone.owner_id) {
+            const points = match.points[zone.owner_id] || 0;
+            status = `${sideLabel(zone.owner_id)} ${points}/${match.score_to_win}`;
+        }
+
+        const mine = mySide ? match.points[mySide] || 0 : 0;
+        matchText.text = `HILL: ${status}  -  YOU: ${mine}  -  ${seconds}s\nMOVES IN ${Math.ceil(zone.next_move)}s`;
+    }
+
     updateHealthBar(localPlayer.health, localPlayer.maxHealth);
     updateSetProgress([]);
 })();
@@ -1,10 +1,21 @@
+const PROTOCOL_VERSION = 1;
+const CLIENT_FEATURES = ["prediction"];
+
 export class NetworkManager {
-    constructor(onGameState, onCardOffer) {
+    constructor(onGameState, onCardOffer, onCardAutoPicked) {
         this.ws = null;
         this.connected = false;
         this.onGameState = onGameState;
         this.onCardOffer = onCardOffer;
+        this.onCardAutoPicked = onCardAutoPicked;
+        this.onMap = () => {};
+        this.onChat = () => {};
+        this.onOrbEvent = () => {};
         this.myPlayerID = null;
+        this.features = [];
+        this.versionMismatch = false;
+        this.spectating =
+            new URLSearchParams(location.search).get("spectate") === "1";
     }
 
     connect() {
@@ -17,7 +28,8 @@ export class NetworkManager {
                 ? 6767
                 : location.port || 443;
 
-        const url = `${protocol}://${host}:${port}/ws`;
+        const query = this.spectating ? "?spectate=1" : "";
+        const url = `${protocol}://${host}:${port}/ws${query}`;
         console.log("[WS] Connecting to", url);
 
         this.ws = new WebSocket(url);
@@ -25,6 +37,15 @@ export class NetworkManager {
         this.ws.onopen = () => {
             console.log("connected to server");
             this.connected = true;
+            this.ws.send(
+                JSON.stringify({
+                    type: "hello",
+                    data: {
+                        version: PROTOCOL_VERSION,
+                        features: CLIENT_FEATURES,
+                    },
+                }),
+            );
         };
 
         this.ws.onmessage = (event) => {
@@ -36,27 +57,70 @@ export class NetworkManager {
             console.error("websocket error", error);
         };
 
-        this.ws.onclose = () => {
-            console.log("disconnected from server");
+        this.ws.onclose = (event) => {
+            console.log("disconnected from server", event.reason || "");
             this.connected = false;
             this.myPlayerID = null;
+            if (this.versionMismatch) return;
             setTimeout(() => this.connect(), 3000);
         };
     }
 
     handleMessage(msg) {
         switch (msg.type) {
+            case "error":
+                console.warn(`server rejected ${msg.data.type}:`, msg.data.message);
+                break;
+
+            case "version_mismatch":
+                this.versionMismatch = true;
+                console.error(
+                    `protocol ${msg.data.client_version} is not supported by the server (${msg.data.min_version}-${msg.data.server_version}), reload the page`,
+                );
+                break;
+
             case "welcome":
                 this.myPlayerID = msg.data.player_id;
+                this.features = msg.data.features || [];
                 console.log("my id: ", this.myPlayerID);
                 break;
 
+            case "map":
+                this.onMap(msg.data);
+                break;
+
+            case "match_phase":
+            case "koth_update":
+                break;
+
+            case "chat":
+                this.onChat(msg.data);
+                break;
+
+            case "chat_history":
+                for (const entry of msg.data.messages || []) {
+                    this.onChat(entry);
+                }
+                break;
+
+            case "orb_event":
+                this.onOrbEvent(msg.data);
+                break;
+
             case "game_state":
                 this.onGameState(msg.data);
                 break;
 
             case "card_offer":
-                this.onCardOffer(msg.data.cards);
+                this.onCardOffer(
+                    msg.data.cards,
+                    msg.data.rerolls || 0,
+                    msg.data.timeout || 0,
+                );
+                break;
+
+            case "card_auto_picked":
+                this.onCardAutoPicked(msg.data.card);
                 break;
 
             default:
@@ -85,4 +149,81 @@ export class NetworkManager {
             }),
         );
     }
+
+    sendSpectate(mode, followID, x, y) {
+        if (!this.connected) return;
+
+        this.ws.send(
+            JSON.stringify({
+                type: "spectate",
+                data: { mode, follow_id: followID || "", x: x || 0, y: y || 0 },
+            }),
+        );
+    }
+
+    sendChat(text, scope = "all") {
+        if (!this.connected) return;
+
+        this.ws.send(
+            JSON.stringify({
+                type: "chat",
+                data: { text, scope },
+            }),
+        );
+    }
+
+    sendChatBlock(playerID, blocked) {
+        if (!this.connected) return;
+
+        this.ws.send(
+            JSON.stringify({
+                type: "chat_block",
+                data: { player_id: playerID, blocked },
+            }),
+        );
+    }
+
+    sendEmote(emote) {
+        if (!this.connected) return;
+
+        this.ws.send(
+            JSON.stringify({
+                type: "emote",
+                data: { emote },
+            }),
+        );
+    }
+
+    sendAbility() {
+        if (!this.connected) return;
+
+        this.ws.send(
+            JSON.stringify({
+                type: "ability",
+                data: {},
+            }),
+        );
+    }
+
+    sendCardReroll() {
+        if (!this.connected) return;
+
+        this.ws.send(
+            JSON.stringify({
+                type: "card_reroll",
+                data: {},
+            }),
+        );
+    }
+
+    sendCardBanish(cardID) {
+        if (!this.connected) return;
+
+        this.ws.send(
+            JSON.stringify({
+                type: "card_banish",
+                data: { card_id: cardID },
+            }),
+        );
+    }
 }
@@ -1,4 +1,14 @@
-import { Graphics, Container } from "pixi.js";
+import { Graphics, Container, Text } from "pixi.js";
+
+const EMOTE_LABELS = {
+    taunt: "COME AT ME",
+    gg: "GG",
+    help: "HELP!",
+    skull: "FIRST BLOOD",
+    crown: "ALL HAIL",
+    sparkle: "SHINY",
+    fire: "ON FIRE",
+};
 
 export class Renderer {
     constructor(app, world) {
@@ -7,6 +17,13 @@ export class Renderer {
 
         this.playerGraphics = new Map();
         this.pelletGraphics = new Map();
+        this.projectileGraphics = new Map();
+        this.mapGraphics = null;
+        this.hazardGraphics = new Map();
+        this.safeZoneGraphic = null;
+        this.captureZoneGraphic = null;
+        this.objectiveGraphic = null;
+        this.emoteBubbles = new Map();
         this.localPlayerGraphic = null;
         this.localPlayerAuraGraphic = null;
 
@@ -40,6 +57,135 @@ export class Renderer {
 
         this.renderPlayers(gameState.players);
         this.renderPellets(gameState.pellets);
+        this.renderProjectiles(gameState.projectiles || []);
+        this.renderHazards(gameState.hazards || []);
+        this.renderSafeZone(gameState.match && gameState.match.safe_zone);
+        this.renderCaptureZone(gameState.match && gameState.match.zone);
+        this.renderObjectives(gameState.match);
+        this.renderEmotes(gameState.players);
+    }
+
+    renderEmotes(players) {
+        const active = new Set();
+
+        for (const player of players) {
+            if (!player.emote) continue;
+            active.add(player.id);
+
+            let bubble = this.emoteBubbles.get(player.id);
+            if (!bubble) {
+                bubble = new Text({
+                    text: "",
+                    style: {
+                        fontFamily: "Virgil",
+                        fontSize: 22,
+                        fill: 0x333333,
+                        fontWeight: "bold",
+                        stroke: { color: 0xffffff, width: 4 },
+                    },
+                });
+                bubble.anchor.set(0.5, 1);
+                this.world.addChild(bubble);
+                this.emoteBubbles.set(player.id, bubble);
+            }
+
+            bubble.text = EMOTE_LABELS[player.emote] || player.emote;
+
+            let x = player.x;
+            let y = player.y;
+            if (player.id === this.myPlayerID && this.localPlayerGraphic) {
+                x = this.localPlayerGraphic.position.x;
+                y = this.localPlayerGraphic.position.y;
+            }
+            bubble.position.set(x, y - player.size - 25);
+        }
+
+        for (const [id, bubble] of this.emoteBubbles) {
+            if (!active.has(id)) {
+                bubble.destroy();
+                this.emoteBubbles.delete(id);
+            }
+        }
+    }
+
+    renderObjectives(match) {
+        if (!this.objectiveGraphic) {
+            this.objectiveGraphic = new Graphics();
+            this.world.addChild(this.objectiveGraphic);
+        }
+
+        this.objectiveGraphic.clear();
+        if (!match || match.mode !== "cto") return;
+
+        const teamColors = {
+            red: 0xdd3333,
+            blue: 0x3333dd,
+        };
+
+        for (const base of match.bases) {
+            const color = teamColors[base.team] || 0x777777;
+            this.objectiveGraphic.circle(base.x, base.y, base.radius);
+            this.objectiveGraphic.fill({ color, alpha: 0.1 });
+            this.objectiveGraphic.stroke({ width: 4, color, alpha: 0.6 });
+        }
+
+        for (const orb of match.orbs) {
+            const color = teamColors[orb.team] || 0x777777;
+            const size = orb.size + this.cachedSin * 3;
+            this.objectiveGraphic.circle(orb.x, orb.y, size);
+            this.objectiveGraphic.fill({ color, alpha: orb.carrier_id ? 0.5 : 0.9 });
+            this.objectiveGraphic.stroke({ width: 3, color: 0xffffff, alpha: 0.8 });
+        }
+    }
+
+    renderCaptureZone(zone) {
+        if (!this.captureZoneGraphic) {
+            this.captureZoneGraphic = new Graphics();
+            this.world.addChildAt(this.captureZoneGraphic, 1);
+        }
+
+        this.captureZoneGraphic.clear();
+        if (!zone) return;
+
+        const teamColors = {
+            red: 0xdd3333,
+            blue: 0x3333dd,
+        };
+
+        let color = 0x777777;
+        if (zone.contested) {
+            color = 0xffaa33;
+        } else if (zone.owner_id === this.myPlayerID) {
+            color = 0x77cc77;
+        } else if (zone.owner_id) {
+            color = teamColors[zone.owner_id] || 0xcc7777;
+        }
+
+        this.captureZoneGraphic.circle(zone.x, zone.y, zone.radius);
+        this.captureZoneGraphic.fill({ color, alpha: 0.15 });
+        this.captureZoneGraphic.stroke({ width: 6, color, alpha: 0.7 });
+    }
+
+    renderSafeZone(zone) {
+        if (!this.safeZoneGraphic) {
+            this.safeZoneGraphic = new Graphics();
+            this.world.addChild(this.safeZoneGraphic);
+        }
+
+        this.safeZoneGraphic.clear();
+        if (!zone) return;
+
+        this.safeZoneGraphic.circle(zone.x, zone.y, zone.radius);
+        this.safeZoneGraphic.stroke({ width: 8, color: 0x4488ff, alpha: 0.8 });
+
+        if (zone.target_radius < zone.radius) {
+            this.safeZoneGraphic.circle(zone.x, zone.y, zone.target_radius);
+            this.safeZoneGraphic.stroke({
+                width: 3,
+                color: 0xffffff,
+                alpha: 0.6,
+            });
+        }
     }
 
     renderPlayers(players) {
@@ -96,6 +242,7 @@ export class Renderer {
 
             if (this.animationFrame - graphic.lastAuraFrame > 2) {
                 graphic.auraGraphics.clear();
+                this.renderTeamRing(player, graphic.auraGraphics);
                 this.renderPlayerAuras(player, graphic.auraGraphics);
                 this.renderBarrier(player, graphic.auraGraphics);
                 this.renderActiveEffects(player, graphic.auraGraphics);
@@ -246,7 +393,31 @@ export class Renderer {
             if (!graphic) {
                 graphic = new Graphics();
                 graphic.circle(0, 0, pellet.size);
-                graphic.fill({ color: 0x7777cc, alpha: 0.5 });
+
+                switch (pellet.type) {
+                    case "golden":
+                        graphic.fill({ color: 0xffcc33, alpha: 0.9 });
+                        graphic.stroke({ width: 2, color: 0xdd8811 });
+                        break;
+                    case "speed":
+                        graphic.fill({ color: 0x44ffff, alpha: 0.8 });
+                        graphic.stroke({ width: 2, color: 0x333333 });
+                        break;
+                    case "invulnerable":
+                        graphic.fill({ color: 0xffffff, alpha: 0.9 });
+                        graphic.stroke({ width: 2, color: 0x4488ff });
+                        break;
+                    case "double_score":
+                        graphic.fill({ color: 0xcc77cc, alpha: 0.8 });
+                        graphic.stroke({ width: 2, color: 0x333333 });
+                        break;
+                    case "remains":
+                        graphic.fill({ color: 0xcc7777, alpha: 0.6 });
+                        break;
+                    default:
+                        graphic.fill({ color: 0x7777cc, alpha: 0.5 });
+                }
+
                 this.world.addChild(graphic);
                 this.pelletGraphics.set(pellet.id, graphic);
             }
@@ -255,6 +426,104 @@ export class Renderer {
         }
     }
 
+    renderMap(gameMap) {
+        if (this.mapGraphics) {
+            this.mapGraphics.destroy();
+        }
+
+        this.mapGraphics = new Graphics();
+
+        for (const zone of gameMap.pellet_zones || []) {
+            this.mapGraphics.circle(zone.x, zone.y, zone.radius);
+            this.mapGraphics.fill({ color: 0x7777cc, alpha: 0.06 });
+        }
+
+        for (const wall of gameMap.walls || []) {
+            switch (wall.type) {
+                case "circle":
+                    this.mapGraphics.circle(wall.x, wall.y, wall.radius);
+                    break;
+                case "rect":
+                    this.mapGraphics.rect(
+                        wall.x - wall.width / 2,
+                        wall.y - wall.height / 2,
+                        wall.width,
+                        wall.height,
+                    );
+                    break;
+                case "polygon":
+                    this.mapGraphics.poly(wall.points.flat());
+                    break;
+                default:
+                    continue;
+            }
+            this.mapGraphics.fill({ color: 0x777777, alpha: 0.35 });
+            this.mapGraphics.stroke({ width: 3, color: 0x333333 });
+        }
+
+        this.world.addChildAt(this.mapGraphics, 1);
+    }
+
+    renderHazards(hazards) {
+        const currentIDs = new Set(hazards.map((h) => h.id));
+
+        for (const [id, graphic] of this.hazardGraphics) {
+            if (!currentIDs.has(id)) {
+                graphic.destroy();
+                this.hazardGraphics.delete(id);
+            }
+        }
+
+        const colors = {
+            lava: 0xff4444,
+            mud: 0x886644,
+            spring: 0x44ffff,
+            danger: 0xcc33cc,
+        };
+
+        for (const hazard of hazards) {
+            let graphic = this.hazardGraphics.get(hazard.id);
+
+            if (!graphic) {
+                const color = colors[hazard.type] || 0x777777;
+                graphic = new Graphics();
+                graphic.circle(0, 0, hazard.radius);
+                graphic.fill({ color, alpha: 0.2 });
+                graphic.stroke({ width: 3, color, alpha: 0.6 });
+                this.world.addChildAt(graphic, 1);
+                this.hazardGraphics.set(hazard.id, graphic);
+            }
+
+            graphic.position.set(hazard.x, hazard.y);
+        }
+    }
+
+    renderProjectiles(projectiles) {
+        const currentIDs = new Set(projectiles.map((p) => p.id));
+
+        for (const [id, graphic] of this.projectileGraphics) {
+            if (!currentIDs.has(id)) {
+                graphic.destroy();
+                this.projectileGraphics.delete(id);
+            }
+        }
+
+        for (const projectile of projectiles) {
+            let graphic = this.projectileGraphics.get(projectile.id);
+
+            if (!graphic) {
+                graphic = new Graphics();
+                graphic.circle(0, 0, projectile.size);
+                graphic.fill({ color: 0x333333, alpha: 0.8 });
+                graphic.stroke({ width: 2, color: 0xcc7777 });
+                this.world.addChild(graphic);
+                this.projectileGraphics.set(projectile.id, graphic);
+            }
+
+            graphic.position.set(projectile.x, projectile.y);
+        }
+    }
+
     getCardEffectLayers(appliedCards) {
         const effectDefinitions = {
             poison: {
@@ -988,6 +1257,18 @@ export class Renderer {
                 case "lifesteal":
                     color = 0xff0000;
                     break;
+                case "knockback":
+                    color = 0xffaa33;
+                    break;
+                case "pull":
+                    color = 0x8844cc;
+                    break;
+                case "magnet":
+                    color = 0x7777cc;
+                    break;
+                case "heal_allies":
+                    color = 0x44ffff;
+                    break;
             }
 
             graphics.circle(0, 0, aura.radius);
@@ -999,6 +1280,19 @@ export class Renderer {
         }
     }
 
+    renderTeamRing(player, graphics) {
+        if (!player.team_id) return;
+
+        const teamColors = {
+            red: 0xdd3333,
+            blue: 0x3333dd,
+        };
+        const color = teamColors[player.team_id] || 0x777777;
+
+        graphics.circle(0, 0, player.size + 4);
+        graphics.stroke({ width: 4, color, alpha: 0.7 });
+    }
+
     renderBarrier(player, graphics) {
         if (!player.barrier || player.barrier <= 0 || !player.max_barrier)
             return;
@@ -1045,6 +1339,15 @@ export class Renderer {
                 case "regen":
                     color = 0x44ffff;
                     break;
+                case "speed":
+                    color = 0x44ffff;
+                    break;
+                case "invulnerable":
+                    color = 0x4488ff;
+                    break;
+                case "double_score":
+                    color = 0xcc77cc;
+                    break;
             }
 
             for (let i = 0; i < particleCount; i++) {